@@ -0,0 +1,242 @@
+// Package analysis turns text into terms with a configurable pipeline: char
+// filters transform the whole text, a tokeniser splits it into tokens, and
+// token filters transform (or drop) each token in order.
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Config declares an analyser. It is stored in the index, so that queries
+// can be analysed the same way as the indexed documents.
+type Config struct {
+	CharFilters  []string       `json:"char_filters"`
+	Tokeniser    string         `json:"tokeniser"`
+	TokenFilters []FilterConfig `json:"token_filters"`
+
+	// Languages holds a config for each language. If there are any, the
+	// language of every text is detected, and the text is analysed with the
+	// config of its language, or with this one if there is none.
+	Languages map[string]*Config `json:"languages,omitempty"`
+}
+
+// FilterConfig declares a token filter. Only the fields which apply to
+// its type are used.
+type FilterConfig struct {
+	Type string `json:"type"`
+
+	// Language of the stemmer
+	Language string `json:"language,omitempty"`
+
+	// Words of the stop word filter. When reading a config, the words in
+	// WordsFile (one per line) are added to them, so that the index
+	// doesn't depend on the file.
+	Words     []string `json:"words,omitempty"`
+	WordsFile string   `json:"words_file,omitempty"`
+
+	// Bounds of the length filter, in characters. Max is unlimited if 0.
+	Min int `json:"min,omitempty"`
+	Max int `json:"max,omitempty"`
+
+	// Replacement of numbers by the number filter, "#" if empty
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// ReadConfigFile reads an analyser config from a JSON file
+func ReadConfigFile(filename string) (*Config, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file: %s", err)
+	}
+	defer f.Close()
+
+	config := &Config{}
+	err = json.NewDecoder(f).Decode(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse analyser config %s: %s", filename, err)
+	}
+
+	err = config.readWordsFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// ParseConfig decodes a config encoded with Encode
+func ParseConfig(data []byte) (*Config, error) {
+	config := &Config{}
+	err := json.Unmarshal(data, config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse analyser config: %s", err)
+	}
+	return config, nil
+}
+
+// Encode encodes the config as JSON, e.g. to be stored in an index
+func (c *Config) Encode() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+func (c *Config) readWordsFiles() error {
+	for i := range c.TokenFilters {
+		filter := &c.TokenFilters[i]
+		if filter.WordsFile == "" {
+			continue
+		}
+
+		words, err := readWordsFile(filter.WordsFile)
+		if err != nil {
+			return err
+		}
+		filter.Words = append(filter.Words, words...)
+		filter.WordsFile = ""
+	}
+
+	for _, language := range c.Languages {
+		err := language.readWordsFiles()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Analyser runs the pipeline of a config. It has the methods of
+// processing.Tokeniser, so it can be used in its place.
+type Analyser struct {
+	Config *Config
+
+	charFilters  []CharFilter
+	tokeniser    WordTokeniser
+	tokenFilters []TokenFilter
+	stopWords    []*StopWordFilter
+	languages    map[string]*Analyser
+
+	// fallback is the pipeline of a multilingual analyser without language
+	// detection, for texts in languages without a pipeline of their own
+	fallback *Analyser
+}
+
+// New creates the analyser declared by the config
+func New(config *Config) (*Analyser, error) {
+	a := &Analyser{Config: config}
+
+	for _, name := range config.CharFilters {
+		filter, err := NewCharFilter(name)
+		if err != nil {
+			return nil, err
+		}
+		a.charFilters = append(a.charFilters, filter)
+	}
+
+	tokeniser, err := NewWordTokeniser(config.Tokeniser)
+	if err != nil {
+		return nil, err
+	}
+	a.tokeniser = tokeniser
+
+	for i := range config.TokenFilters {
+		filter, err := NewTokenFilter(&config.TokenFilters[i])
+		if err != nil {
+			return nil, err
+		}
+		a.tokenFilters = append(a.tokenFilters, filter)
+
+		if stopWords, ok := filter.(*StopWordFilter); ok {
+			a.stopWords = append(a.stopWords, stopWords)
+		}
+	}
+
+	if len(config.Languages) > 0 {
+		fallback := *a
+		fallbackConfig := *config
+		fallbackConfig.Languages = nil
+		fallback.Config = &fallbackConfig
+		a.fallback = &fallback
+
+		a.languages = make(map[string]*Analyser, len(config.Languages))
+		for language, languageConfig := range config.Languages {
+			analyser, err := New(languageConfig)
+			if err != nil {
+				return nil, fmt.Errorf("analyser for %s: %s", language, err)
+			}
+			a.languages[language] = analyser
+		}
+	}
+
+	return a, nil
+}
+
+// Multilingual tells if the analyser has a separate pipeline per language
+func (a *Analyser) Multilingual() bool {
+	return len(a.languages) > 0
+}
+
+// ForLanguage returns the analyser for texts in the language. It never
+// detects the language again: for languages without a separate pipeline,
+// a multilingual analyser returns its own pipeline without detection, and
+// other analysers return themselves.
+func (a *Analyser) ForLanguage(language string) *Analyser {
+	if analyser, ok := a.languages[language]; ok {
+		return analyser
+	}
+	if a.fallback != nil {
+		return a.fallback
+	}
+	return a
+}
+
+// Tokenise applies the char filters and the tokeniser
+func (a *Analyser) Tokenise(text string) []string {
+	for _, filter := range a.charFilters {
+		text = filter.Filter(text)
+	}
+	return a.tokeniser.Tokenise(text)
+}
+
+// Normalise applies the token filters, and returns "" if one of them drops
+// the token
+func (a *Analyser) Normalise(token string) string {
+	for _, filter := range a.tokenFilters {
+		token = filter.Filter(token)
+		if token == "" {
+			return ""
+		}
+	}
+	return token
+}
+
+// IsStopWord tells if a normalised word should be skipped
+func (a *Analyser) IsStopWord(word string) bool {
+	if word == "" {
+		return true
+	}
+	for _, stopWords := range a.stopWords {
+		if stopWords.Filter(word) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTerms calls operation with every term of the text, in order. A
+// multilingual analyser detects the language of the text first.
+func (a *Analyser) GetTerms(text string, operation func(string)) {
+	if a.Multilingual() {
+		a.ForLanguage(DetectLanguage(text)).GetTerms(text, operation)
+		return
+	}
+
+	for _, token := range a.Tokenise(text) {
+		term := a.Normalise(token)
+		if term == "" {
+			continue
+		}
+		operation(term)
+	}
+}
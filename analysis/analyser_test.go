@@ -0,0 +1,271 @@
+package analysis
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func terms(a *Analyser, text string) []string {
+	var all []string
+	a.GetTerms(text, func(term string) {
+		all = append(all, term)
+	})
+	return all
+}
+
+func TestAnalyser(t *testing.T) {
+	assert := assert.New(t)
+
+	a, err := New(&Config{
+		CharFilters: []string{"html", "nfkc", "fold-accents"},
+		Tokeniser:   "unicode",
+		TokenFilters: []FilterConfig{
+			{Type: "lowercase"},
+			{Type: "stopwords", Words: []string{"the", "of"}},
+			{Type: "length", Min: 2},
+			{Type: "numbers"},
+			{Type: "stem", Language: "english"},
+		},
+	})
+	assert.Nil(err)
+
+	assert.Equal(
+		[]string{"Cafe", "prices", "of", "the", "rose", "by", "3,5", "in", "x"},
+		a.Tokenise("<p>Café <b>prices</b> of&nbsp;the rose by 3,5 in<script>x = 1</script> ｘ</p>"),
+	)
+	assert.Equal(
+		[]string{"cafe", "price", "rose", "by", "#", "in"},
+		terms(a, "<p>Café <b>prices</b> of&nbsp;the rose by 3,5 in<script>x = 1</script> ｘ</p>"),
+	)
+
+	assert.True(a.IsStopWord("the"))
+	assert.True(a.IsStopWord(""))
+	assert.False(a.IsStopWord("rose"))
+	assert.Equal("", a.Normalise("x"))
+}
+
+func TestTokenisers(t *testing.T) {
+	assert := assert.New(t)
+
+	whitespace, err := NewWordTokeniser("whitespace")
+	assert.Nil(err)
+	assert.Equal([]string{"don't", "stop-words,", "ok?"}, whitespace.Tokenise(" don't\tstop-words, ok?"))
+
+	unicode, err := NewWordTokeniser("unicode")
+	assert.Nil(err)
+	assert.Equal([]string{"Здравей", "свят", "42"}, unicode.Tokenise("Здравей, свят! 42"))
+	assert.Equal([]string{"pi", "3.14", "1", "2"}, unicode.Tokenise("pi=3.14, 1, 2."))
+
+	_, err = NewWordTokeniser("nonexistent")
+	assert.NotNil(err)
+
+	_, err = New(&Config{TokenFilters: []FilterConfig{{Type: "stem", Language: "klingon"}}})
+	assert.NotNil(err)
+}
+
+func TestReadConfigFile(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "analysis")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	stopWords := filepath.Join(dir, "stopwords")
+	assert.Nil(ioutil.WriteFile(stopWords, []byte("a\nthe\n\n"), 0644))
+
+	config := filepath.Join(dir, "analyser.json")
+	assert.Nil(ioutil.WriteFile(config, []byte(`{
+		"tokeniser": "whitespace",
+		"token_filters": [
+			{"type": "lowercase"},
+			{"type": "stopwords", "words": ["an"], "words_file": "`+stopWords+`"}
+		]
+	}`), 0644))
+
+	c, err := ReadConfigFile(config)
+	assert.Nil(err)
+	assert.Equal(&Config{
+		Tokeniser: "whitespace",
+		TokenFilters: []FilterConfig{
+			{Type: "lowercase"},
+			{Type: "stopwords", Words: []string{"an", "a", "the"}},
+		},
+	}, c)
+
+	a, err := New(c)
+	assert.Nil(err)
+	assert.Equal([]string{"cat", "dog"}, terms(a, "A cat an THE dog"))
+}
+
+func TestStemmers(t *testing.T) {
+	assert := assert.New(t)
+
+	german := map[string]string{
+		"häuser":       "haus",
+		"katzen":       "katz",
+		"laufen":       "lauf",
+		"Straße":       "strass",
+		"ordnung":      "ordnung",
+		"freundlichen": "freundlich",
+		"bedeutung":    "bedeut",
+		"kenntnisse":   "kenntnis",
+	}
+	for word, stem := range german {
+		assert.Equal(stem, StemGerman(word), word)
+	}
+
+	// sample vocabulary and stems from the Snowball German algorithm page
+	snowballGerman := map[string]string{
+		"aufeinanderfolge":          "aufeinanderfolg",
+		"aufeinanderfolgen":         "aufeinanderfolg",
+		"aufeinanderfolgend":        "aufeinanderfolg",
+		"aufeinanderfolgende":       "aufeinanderfolg",
+		"aufeinanderfolgenden":      "aufeinanderfolg",
+		"aufeinanderfolgenderweise": "aufeinanderfolgenderweis",
+		"aufeinanderfolgten":        "aufeinanderfolgt",
+		"aufeinanderschlügen":       "aufeinanderschlug",
+		"aufenthalt":                "aufenthalt",
+		"aufenthalten":              "aufenthalt",
+		"aufenthaltes":              "aufenthalt",
+		"auferlegen":                "auferleg",
+		"auferlegt":                 "auferlegt",
+		"auferlegten":               "auferlegt",
+		"auferstand":                "auferstand",
+		"auferstanden":              "auferstand",
+		"auferstehen":               "aufersteh",
+		"aufersteht":                "aufersteht",
+		"auferstehung":              "aufersteh",
+		"kategorische":              "kategor",
+		"kategorischen":             "kategor",
+		"kategorischer":             "kategor",
+		"kein":                      "kein",
+		"keine":                     "kein",
+		"keinem":                    "kein",
+		"keinen":                    "kein",
+		"keiner":                    "kein",
+		"keines":                    "kein",
+		"keinesfalls":               "keinesfall",
+		"keineswegs":                "keinesweg",
+	}
+	for word, stem := range snowballGerman {
+		assert.Equal(stem, StemGerman(word), word)
+	}
+
+	bulgarian := map[string]string{
+		"книгата":     "книг",
+		"книги":       "книг",
+		"градовете":   "град",
+		"градът":      "град",
+		"град":        "град",
+		"красивият":   "красив",
+		"красиви":     "красив",
+		"българските": "българск",
+		"български":   "българск",
+		"четат":       "чет",
+		"ще":          "ще",
+	}
+	for word, stem := range bulgarian {
+		assert.Equal(stem, StemBulgarian(word), word)
+	}
+}
+
+func TestPresets(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, language := range PresetNames() {
+		config, err := Load(language)
+		assert.Nil(err, language)
+		_, err = New(config)
+		assert.Nil(err, language)
+	}
+
+	config, err := Load("bulgarian")
+	assert.Nil(err)
+	a, err := New(config)
+	assert.Nil(err)
+	assert.Equal(
+		[]string{"книг", "българск", "автор", "2018"},
+		terms(a, "Книгата на българските автори е от 2018 г."),
+	)
+
+	config, err = Load("russian")
+	assert.Nil(err)
+	a, err = New(config)
+	assert.Nil(err)
+	assert.Equal([]string{"книг", "очен", "интересн"}, terms(a, "Эти книги очень интересные"))
+
+	_, err = Load("klingon")
+	assert.NotNil(err)
+}
+
+func TestDetectLanguage(t *testing.T) {
+	assert := assert.New(t)
+
+	texts := map[string]string{
+		"english":   "The company said its profits rose in the last quarter of the year.",
+		"bulgarian": "Президентът заяви, че страната няма да промени своята позиция по въпроса.",
+		"russian":   "Президент заявил, что страна не изменит свою позицию по этому вопросу.",
+		"german":    "Der Präsident erklärte, dass das Land seine Haltung nicht ändern werde.",
+		"french":    "Le président a déclaré que le pays ne changerait pas sa position.",
+		"spanish":   "El presidente dijo que el país no cambiará su posición sobre el tema.",
+	}
+	for language, text := range texts {
+		assert.Equal(language, DetectLanguage(text), text)
+	}
+
+	assert.Equal("", DetectLanguage("42 + 17 = 59"))
+}
+
+func TestDetectLanguageHeldOut(t *testing.T) {
+	assert := assert.New(t)
+
+	// none of these sentences are in the training samples; the Cyrillic
+	// ones come in Russian and Bulgarian pairs with the same meaning
+	texts := []struct{ language, text string }{
+		{"russian", "Президент республики подписал указ сегодня."},
+		{"bulgarian", "Президентът на републиката подписа указа днес."},
+		{"russian", "Вчера вечером мы долго гуляли по набережной и разговаривали о жизни."},
+		{"bulgarian", "Вчера вечерта дълго се разхождахме по брега и си говорихме за живота."},
+		{"russian", "Студенты сдают экзамены в июне, а потом уезжают домой на каникулы."},
+		{"bulgarian", "Студентите полагат изпити през юни, а после се прибират у дома за ваканцията."},
+		{"russian", "Мой брат купил новую машину."},
+		{"bulgarian", "Брат ми си купи нова кола."},
+
+		{"english", "The children play in the garden after school."},
+		{"german", "Die Kinder spielen nach der Schule im Garten."},
+		{"french", "Les enfants jouent dans le jardin après l'école."},
+		{"spanish", "Los niños juegan en el jardín después de la escuela."},
+		{"german", "Es regnet seit heute Morgen."},
+		{"french", "Il pleut depuis ce matin."},
+		{"spanish", "Llueve desde esta mañana."},
+	}
+	for _, text := range texts {
+		assert.Equal(text.language, DetectLanguage(text.text), text.text)
+	}
+}
+
+func TestMultilingual(t *testing.T) {
+	assert := assert.New(t)
+
+	config, err := Load("multilingual")
+	assert.Nil(err)
+	a, err := New(config)
+	assert.Nil(err)
+	assert.True(a.Multilingual())
+
+	assert.Equal([]string{"книг", "българск", "автор"}, terms(a, "Книгата на българските автори"))
+	assert.Equal([]string{"book", "bulgarian", "author"}, terms(a, "The books of the Bulgarian authors"))
+	assert.Equal(a.ForLanguage("german").Config, config.Languages["german"])
+
+	// languages without a pipeline get the common one, which doesn't
+	// detect the language again
+	klingon := a.ForLanguage("klingon")
+	assert.False(klingon.Multilingual())
+	assert.Nil(klingon.Config.Languages)
+	assert.Equal(klingon, a.ForLanguage(""))
+	assert.Equal([]string{"книгата", "на", "българските", "автори"}, terms(klingon, "Книгата на българските автори"))
+}
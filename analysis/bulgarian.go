@@ -0,0 +1,72 @@
+package analysis
+
+import (
+	"strings"
+)
+
+// bulgarianSuffixes are the inflectional suffixes removed by StemBulgarian,
+// with what replaces them. They cover the definite articles and plurals of
+// nouns and adjectives, and the most common verb endings, in the spirit of
+// BulStem (Nakov, 2003), which learns similar rules from a corpus.
+var bulgarianSuffixes = map[string]string{
+	// nouns: plurals and definite articles
+	"овете": "", "евете": "", "ищата": "", "ите": "", "ове": "", "еве": "",
+	"ища": "", "ият": "", "ия": "", "ът": "", "ят": "", "ата": "", "ята": "",
+	"ото": "", "ето": "", "та": "", "то": "", "те": "",
+
+	// adjectives
+	"ската": "ск", "ското": "ск", "ските": "ск", "ският": "ск", "ския": "ск",
+	"ска": "ск", "ско": "ск", "ски": "ск",
+	"ната": "н", "ното": "н", "ните": "н", "ният": "н", "ния": "н",
+	"ена": "ен", "ено": "ен", "ени": "ен",
+
+	// verbs: present and past tenses, participles
+	"ахме": "", "ахте": "", "яхме": "", "яхте": "", "ихме": "", "ихте": "",
+	"аха": "", "яха": "", "иха": "", "аше": "", "еше": "", "ише": "",
+	"аме": "", "ате": "", "яме": "", "яте": "", "ете": "",
+	"ам": "", "ям": "", "ем": "", "им": "", "ат": "", "ах": "", "ях": "", "их": "",
+	"ал": "", "ала": "", "ало": "", "али": "", "ял": "", "яла": "", "яло": "", "яли": "",
+	"ел": "", "ела": "", "ело": "", "ели": "", "ил": "", "ила": "", "ило": "", "или": "",
+	"ащ": "", "ящ": "", "ейки": "", "айки": "", "яйки": "",
+	"ение": "", "ения": "", "ането": "", "ане": "", "ене": "",
+
+	// final vowels
+	"а": "", "я": "", "о": "", "е": "", "и": "", "у": "", "ю": "", "ь": "",
+}
+
+// the longest suffix has 5 letters
+const bulgarianMaxSuffix = 5
+
+// StemBulgarian removes the longest inflectional suffix of a Bulgarian word
+// which leaves a stem of at least 2 letters containing a vowel
+func StemBulgarian(word string) string {
+	w := []rune(strings.ToLower(word))
+
+	for length := bulgarianMaxSuffix; length >= 1; length-- {
+		if len(w)-length < 2 {
+			continue
+		}
+
+		replacement, ok := bulgarianSuffixes[string(w[len(w)-length:])]
+		if !ok {
+			continue
+		}
+
+		stem := w[:len(w)-length]
+		if !containsBulgarianVowel(stem) {
+			continue
+		}
+		return string(stem) + replacement
+	}
+
+	return string(w)
+}
+
+func containsBulgarianVowel(w []rune) bool {
+	for _, r := range w {
+		if strings.ContainsRune("аъоуеияю", r) {
+			return true
+		}
+	}
+	return false
+}
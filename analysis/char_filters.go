@@ -0,0 +1,71 @@
+package analysis
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// CharFilter transforms the text before it is tokenised
+type CharFilter interface {
+	Filter(text string) string
+}
+
+// NewCharFilter returns the char filter with the given name: html, nfkc or
+// fold-accents
+func NewCharFilter(name string) (CharFilter, error) {
+	switch name {
+	case "html":
+		return &HTMLStripFilter{}, nil
+	case "nfkc":
+		return &NFKCFilter{}, nil
+	case "fold-accents":
+		return &AccentFoldingFilter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown char filter %s (available: html, nfkc, fold-accents)", name)
+	}
+}
+
+// HTMLStripFilter removes HTML tags, scripts and styles, and decodes
+// entities
+type HTMLStripFilter struct{}
+
+var (
+	htmlHiddenRegex = regexp.MustCompile(`(?is)<(script|style)\b.*?</(script|style)\s*>|<!--.*?-->`)
+	htmlTagRegex    = regexp.MustCompile(`<[^>]*>`)
+)
+
+func (h *HTMLStripFilter) Filter(text string) string {
+	text = htmlHiddenRegex.ReplaceAllString(text, " ")
+	text = htmlTagRegex.ReplaceAllString(text, " ")
+	return html.UnescapeString(text)
+}
+
+// NFKCFilter normalises the text to Unicode NFKC, which e.g. turns
+// ligatures and full-width letters into ordinary ones
+type NFKCFilter struct{}
+
+func (n *NFKCFilter) Filter(text string) string {
+	return norm.NFKC.String(text)
+}
+
+// AccentFoldingFilter removes diacritics, e.g. turning "café" into "cafe"
+type AccentFoldingFilter struct{}
+
+func (f *AccentFoldingFilter) Filter(text string) string {
+	decomposed := norm.NFD.String(text)
+
+	var folded strings.Builder
+	folded.Grow(len(decomposed))
+	for _, r := range decomposed {
+		if !unicode.Is(unicode.Mn, r) {
+			folded.WriteRune(r)
+		}
+	}
+
+	return norm.NFC.String(folded.String())
+}
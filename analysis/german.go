@@ -0,0 +1,166 @@
+package analysis
+
+import (
+	"strings"
+)
+
+// StemGerman stems a word with the Snowball German stemming algorithm
+// (https://snowballstem.org/algorithms/german/stemmer.html). The snowball
+// package doesn't provide it, so this is a port of the published algorithm
+// rather than the generated Snowball code.
+func StemGerman(word string) string {
+	w := []rune(strings.Replace(strings.ToLower(word), "ß", "ss", -1))
+
+	// u and y between vowels are consonants, which is marked by upper case
+	for i := 1; i+1 < len(w); i++ {
+		if (w[i] == 'u' || w[i] == 'y') && isGermanVowel(w[i-1]) && isGermanVowel(w[i+1]) {
+			w[i] = w[i] - 'a' + 'A'
+		}
+	}
+
+	r1, r2 := regions(w, isGermanVowel)
+	if r1 < 3 {
+		r1 = 3
+	}
+
+	w = germanStep1(w, r1)
+	w = germanStep2(w, r1)
+	w = germanStep3(w, r1, r2)
+
+	for i, r := range w {
+		switch r {
+		case 'U':
+			w[i] = 'u'
+		case 'Y':
+			w[i] = 'y'
+		case 'ä':
+			w[i] = 'a'
+		case 'ö':
+			w[i] = 'o'
+		case 'ü':
+			w[i] = 'u'
+		}
+	}
+
+	return string(w)
+}
+
+func germanStep1(w []rune, r1 int) []rune {
+	suffix := longestSuffix(w, "em", "ern", "er", "e", "en", "es", "s")
+	if suffix == "" || len(w)-runeCount(suffix) < r1 {
+		return w
+	}
+
+	switch suffix {
+	case "em", "ern", "er":
+		return w[:len(w)-runeCount(suffix)]
+	case "e", "en", "es":
+		w = w[:len(w)-runeCount(suffix)]
+		if hasSuffix(w, "niss") {
+			w = w[:len(w)-1]
+		}
+		return w
+	default: // s
+		if len(w) >= 2 && strings.ContainsRune("bdfghklmnrt", w[len(w)-2]) {
+			return w[:len(w)-1]
+		}
+		return w
+	}
+}
+
+func germanStep2(w []rune, r1 int) []rune {
+	suffix := longestSuffix(w, "en", "er", "est", "st")
+	if suffix == "" || len(w)-runeCount(suffix) < r1 {
+		return w
+	}
+
+	if suffix == "st" {
+		// the preceding letter has to be a valid st-ending, itself preceded
+		// by at least 3 letters
+		if len(w) >= 6 && strings.ContainsRune("bdfghklmnt", w[len(w)-3]) {
+			return w[:len(w)-2]
+		}
+		return w
+	}
+	return w[:len(w)-runeCount(suffix)]
+}
+
+func germanStep3(w []rune, r1 int, r2 int) []rune {
+	suffix := longestSuffix(w, "end", "ung", "ig", "ik", "isch", "lich", "heit", "keit")
+	if suffix == "" || len(w)-runeCount(suffix) < r2 {
+		return w
+	}
+	stem := w[:len(w)-runeCount(suffix)]
+
+	inR1 := func(suffix string) bool { return len(stem)-runeCount(suffix) >= r1 }
+	inR2 := func(suffix string) bool { return len(stem)-runeCount(suffix) >= r2 }
+
+	switch suffix {
+	case "end", "ung":
+		if hasSuffix(stem, "ig") && inR2("ig") && !hasSuffix(stem[:len(stem)-2], "e") {
+			stem = stem[:len(stem)-2]
+		}
+		return stem
+	case "ig", "ik", "isch":
+		if hasSuffix(stem, "e") {
+			return w
+		}
+		return stem
+	case "lich", "heit":
+		if (hasSuffix(stem, "er") || hasSuffix(stem, "en")) && inR1("er") {
+			stem = stem[:len(stem)-2]
+		}
+		return stem
+	default: // keit
+		switch {
+		case hasSuffix(stem, "lich") && inR2("lich"):
+			stem = stem[:len(stem)-4]
+		case hasSuffix(stem, "ig") && inR2("ig"):
+			stem = stem[:len(stem)-2]
+		}
+		return stem
+	}
+}
+
+func isGermanVowel(r rune) bool {
+	return strings.ContainsRune("aeiouyäöü", r)
+}
+
+// regions returns the start of the Snowball regions R1 and R2: R1 is after
+// the first non-vowel following a vowel, and R2 is the same within R1
+func regions(w []rune, isVowel func(rune) bool) (int, int) {
+	region := func(start int) int {
+		for i := start + 1; i < len(w); i++ {
+			if !isVowel(w[i]) && isVowel(w[i-1]) {
+				return i + 1
+			}
+		}
+		return len(w)
+	}
+
+	r1 := region(0)
+	return r1, region(r1)
+}
+
+// longestSuffix returns the longest of the suffixes which w has, or ""
+func longestSuffix(w []rune, suffixes ...string) string {
+	longest := ""
+	for _, suffix := range suffixes {
+		if runeCount(suffix) > runeCount(longest) && hasSuffix(w, suffix) {
+			longest = suffix
+		}
+	}
+	return longest
+}
+
+func hasSuffix(w []rune, suffix string) bool {
+	s := []rune(suffix)
+	if len(s) > len(w) {
+		return false
+	}
+	return string(w[len(w)-len(s):]) == suffix
+}
+
+func runeCount(s string) int {
+	return len([]rune(s))
+}
@@ -0,0 +1,108 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// languageProfile holds the frequencies of the character n-grams (of 1 to
+// maxNgram letters) of a language
+type languageProfile struct {
+	counts map[string]int
+	total  int
+}
+
+const (
+	maxNgram = 3
+	// only the beginning of long texts is used for detection
+	maxDetectionRunes = 2000
+)
+
+var (
+	profilesOnce sync.Once
+	profiles     map[string]*languageProfile
+	ngramTypes   int // the number of distinct n-grams in all profiles
+)
+
+// Languages returns the languages which DetectLanguage can tell, sorted
+func Languages() []string {
+	var languages []string
+	for language := range languageSamples {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// DetectLanguage returns the most likely language of the text, using a
+// naive Bayes classifier over character n-grams trained on samples of
+// every language. It returns "" if the text has no letters.
+func DetectLanguage(text string) string {
+	profilesOnce.Do(trainProfiles)
+
+	if runes := []rune(text); len(runes) > maxDetectionRunes {
+		text = string(runes[:maxDetectionRunes])
+	}
+
+	scores := make(map[string]float64, len(profiles))
+	found := false
+	eachNgram(text, func(ngram string) {
+		found = true
+		for language, profile := range profiles {
+			probability := float64(profile.counts[ngram]+1) / float64(profile.total+ngramTypes)
+			scores[language] += math.Log(probability)
+		}
+	})
+	if !found {
+		return ""
+	}
+
+	best := ""
+	for _, language := range Languages() {
+		if best == "" || scores[language] > scores[best] {
+			best = language
+		}
+	}
+	return best
+}
+
+func trainProfiles() {
+	profiles = make(map[string]*languageProfile)
+	types := make(map[string]struct{})
+
+	for language, sample := range languageSamples {
+		profile := &languageProfile{counts: make(map[string]int)}
+		eachNgram(sample, func(ngram string) {
+			profile.counts[ngram] += 1
+			profile.total += 1
+			types[ngram] = struct{}{}
+		})
+		profiles[language] = profile
+	}
+
+	ngramTypes = len(types)
+}
+
+// eachNgram calls operation with all n-grams of the lowercased words of
+// the text, which are padded with spaces so that n-grams at the beginning
+// and end of words are distinct
+func eachNgram(text string, operation func(string)) {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	for _, word := range words {
+		padded := []rune(" " + word + " ")
+		for n := 1; n <= maxNgram; n++ {
+			for i := 0; i+n <= len(padded); i++ {
+				if n == 1 && padded[i] == ' ' {
+					continue
+				}
+				operation(string(padded[i : i+n]))
+			}
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package analysis
+
+// languageSamples are the texts the language profiles are trained on: the
+// first article of the Universal Declaration of Human Rights and several
+// paragraphs of news, weather, sport, science and everyday writing, in each
+// language with a built-in analyser. Closely related languages (such as
+// Russian and Bulgarian) are told apart by frequent short words and word
+// endings, so each sample has to be long enough to include many of them.
+var languageSamples = map[string]string{
+	"english": `All human beings are born free and equal in dignity and rights. They
+are endowed with reason and conscience and should act towards one another in a
+spirit of brotherhood. English is a West Germanic language which is spoken in
+the United Kingdom, the United States and many other countries. The finance
+ministry said that the budget for the next year would be approved in December.
+Oil prices rose after the decision of the producing countries. This is the
+largest increase since the beginning of the year. The government promised to
+support small and medium businesses, while the central bank kept its interest
+rates unchanged. Shares of the company fell sharply on Wednesday after it
+reported lower than expected earnings for the third quarter.
+
+The prime minister told parliament on Tuesday that the new law would protect
+workers who lose their jobs, but the opposition said it did not go far enough.
+Members of both parties have asked for more time to read the proposal before
+they vote on it. Several thousand people gathered outside the building to
+demand higher wages and better conditions in the hospitals.
+
+Heavy rain is expected across the north of the country this weekend, with
+strong winds along the coast and snow on the higher hills. Temperatures will
+fall below freezing at night, and drivers have been warned to take care on the
+roads. The weather should become warmer and drier by the middle of next week.
+
+The home team scored twice in the last ten minutes to win the match and move
+to the top of the league. Their coach said he was proud of the players, who
+had never given up even when they were losing at half time. The next game will
+be played away from home against last season's champions.
+
+Scientists at the university have discovered a new species of frog in the
+forests of the south. The animal is smaller than a coin and lives among the
+leaves on the ground. The researchers believe that many other species are still
+unknown, and they have called for the forest to be protected from farming.
+
+My grandmother lived in a small house by the river, where she grew vegetables
+and kept a few chickens. Every summer we would visit her and spend our days
+fishing, reading and walking through the fields. She always had a story to tell
+about the old days, when there were no cars in the village and everybody knew
+each other. I still remember the smell of the bread she used to bake.
+
+The company announced that it would build a new factory which will employ more
+than two thousand people. Production is expected to begin within two years.
+Analysts say that the demand for electric cars has grown quickly, and that the
+prices of batteries have fallen. However, some of them warned that the market
+could slow down if the economy does not recover.
+
+The city council has decided to close the old bridge for repairs, which could
+take up to six months. Buses will be diverted through the centre, and there
+will be more trains during the morning and evening. Local shop owners are
+worried that fewer customers will be able to reach them while the work is done.`,
+
+	"bulgarian": `Всички хора се раждат свободни и равни по достойнство и права. Те
+са надарени с разум и съвест и следва да се отнасят помежду си в дух на
+братство. Българският език е южнославянски език, който се говори главно в
+България. Министерството на финансите съобщи, че бюджетът за следващата година
+ще бъде приет през декември. Цените на петрола се повишиха след решението на
+страните производителки. Това е най-голямото увеличение от началото на
+годината. Правителството обеща да подкрепи малките и средните предприятия, а
+централната банка запази лихвените проценти без промяна. Акциите на компанията
+поевтиняха рязко в сряда, след като тя отчете по-ниска от очакваното печалба за
+третото тримесечие.
+
+Министър-председателят каза пред парламента във вторник, че новият закон ще
+защити работниците, които губят работата си, но опозицията заяви, че това не е
+достатъчно. Депутатите от двете партии поискаха повече време, за да прочетат
+предложението, преди да гласуват. Няколко хиляди души се събраха пред сградата,
+за да искат по-високи заплати и по-добри условия в болниците.
+
+През почивните дни се очаква силен дъжд в северната част на страната, а по
+крайбрежието ще духа силен вятър. В планините ще вали сняг. През нощта
+температурите ще паднат под нулата и шофьорите са предупредени да карат
+внимателно. До средата на следващата седмица времето ще стане по-топло и сухо.
+
+Домакините вкараха два гола в последните десет минути, спечелиха мача и се
+изкачиха на първото място в класирането. Треньорът им каза, че се гордее с
+играчите, които не са се отказали, дори когато губеха на полувремето.
+Следващият мач ще се играе като гост срещу шампиона от миналия сезон.
+
+Учени от университета са открили нов вид жаба в горите на юг. Животното е
+по-малко от монета и живее сред листата по земята. Изследователите смятат, че
+много други видове все още са непознати, и настояват горите да бъдат защитени.
+
+Баба ми живееше в малка къща край реката, където отглеждаше зеленчуци и
+няколко кокошки. Всяко лято ходехме при нея и прекарвахме дните си в риболов,
+четене и разходки из полето. Тя винаги имаше какво да разкаже за старите
+времена, когато в селото нямаше коли и всички се познаваха. Още помня миризмата
+на хляба, който месеше.
+
+Компанията обяви, че ще построи нов завод, в който ще работят повече от две
+хиляди души. Производството се очаква да започне до две години. Според
+анализаторите търсенето на електрически коли расте бързо, а цените на
+батериите падат. Някои от тях обаче предупредиха, че пазарът може да се забави,
+ако икономиката не се възстанови.
+
+Общинският съвет реши да затвори стария мост за ремонт, който може да
+продължи до шест месеца. Автобусите ще минават през центъра, а сутрин и вечер
+ще има повече влакове. Собствениците на магазини се тревожат, че докато трае
+ремонтът, по-малко клиенти ще могат да стигнат до тях. Кметът заяви, че
+общината ще им помогне и че работата ще бъде свършена навреме.`,
+
+	"russian": `Все люди рождаются свободными и равными в своем достоинстве и правах.
+Они наделены разумом и совестью и должны поступать в отношении друг друга в
+духе братства. Русский язык является одним из восточнославянских языков.
+Министерство финансов сообщило, что бюджет на следующий год будет принят в
+декабре. Цены на нефть выросли после решения стран производителей. Это самый
+большой рост с начала года. Правительство обещало поддержать малый и средний
+бизнес, а центральный банк сохранил процентные ставки без изменений. Акции
+компании резко подешевели в среду после того, как она сообщила о более низкой,
+чем ожидалось, прибыли за третий квартал.
+
+Премьер-министр заявил во вторник в парламенте, что новый закон защитит
+работников, которые теряют работу, однако оппозиция считает, что этого
+недостаточно. Депутаты обеих партий попросили больше времени, чтобы прочитать
+предложение, прежде чем голосовать. Несколько тысяч человек собрались у здания,
+требуя повышения зарплат и лучших условий в больницах. Губернатор сказал
+журналистам, что ситуация остается сложной.
+
+В выходные на севере страны ожидаются сильные дожди, а на побережье будет дуть
+сильный ветер. В горах пойдет снег. Ночью температура опустится ниже нуля, и
+водителей предупредили, чтобы они были осторожны на дорогах. К середине
+следующей недели погода станет теплее и суше. Утром в городе было холодно,
+но днем выглянуло солнце.
+
+Хозяева забили два мяча в последние десять минут, выиграли матч и поднялись на
+первое место в таблице. Их тренер сказал, что гордится игроками, которые не
+сдавались, даже когда проигрывали после первого тайма. Следующую игру команда
+проведет на выезде против прошлогоднего чемпиона.
+
+Ученые из университета обнаружили новый вид лягушки в лесах на юге. Это
+животное меньше монеты и живет среди листьев на земле. Исследователи считают,
+что многие другие виды до сих пор неизвестны, и призывают защитить леса от
+вырубки. Результаты работы были опубликованы в научном журнале.
+
+Моя бабушка жила в маленьком доме у реки, где выращивала овощи и держала
+несколько кур. Каждое лето мы приезжали к ней и проводили дни за рыбалкой,
+чтением и прогулками по полям. У нее всегда была история о старых временах,
+когда в деревне не было машин и все знали друг друга. Я до сих пор помню запах
+хлеба, который она пекла.
+
+Компания объявила, что построит новый завод, на котором будут работать более
+двух тысяч человек. Производство должно начаться в течение двух лет. По словам
+аналитиков, спрос на электромобили быстро растет, а цены на аккумуляторы
+снижаются. Однако некоторые из них предупредили, что рынок может замедлиться,
+если экономика не восстановится.
+
+Городской совет решил закрыть старый мост на ремонт, который может продлиться
+до шести месяцев. Автобусы пойдут через центр, а утром и вечером будет больше
+поездов. Владельцы магазинов опасаются, что, пока идет ремонт, к ним сможет
+добраться меньше покупателей. Мэр пообещал, что город поможет им и что работы
+будут закончены вовремя.`,
+
+	"german": `Alle Menschen sind frei und gleich an Würde und Rechten geboren. Sie
+sind mit Vernunft und Gewissen begabt und sollen einander im Geist der
+Brüderlichkeit begegnen. Die deutsche Sprache gehört zum westlichen Zweig der
+germanischen Sprachen. Das Finanzministerium teilte mit, dass der Haushalt für
+das nächste Jahr im Dezember verabschiedet wird. Die Ölpreise stiegen nach der
+Entscheidung der Förderländer. Das ist der größte Anstieg seit Beginn des
+Jahres. Die Regierung versprach, kleine und mittlere Unternehmen zu
+unterstützen, während die Zentralbank die Zinsen unverändert ließ. Die Aktien
+des Unternehmens fielen am Mittwoch deutlich, nachdem es für das dritte Quartal
+einen niedrigeren Gewinn als erwartet gemeldet hatte.
+
+Der Ministerpräsident sagte am Dienstag im Parlament, das neue Gesetz werde
+Arbeitnehmer schützen, die ihre Stelle verlieren, doch die Opposition hält das
+für nicht ausreichend. Abgeordnete beider Parteien baten um mehr Zeit, um den
+Vorschlag zu lesen, bevor sie abstimmen. Mehrere tausend Menschen versammelten
+sich vor dem Gebäude und forderten höhere Löhne und bessere Bedingungen in den
+Krankenhäusern.
+
+Am Wochenende wird im Norden des Landes starker Regen erwartet, an der Küste
+weht ein kräftiger Wind und in den Bergen fällt Schnee. In der Nacht sinken die
+Temperaturen unter null, und Autofahrer werden gebeten, vorsichtig zu fahren.
+Bis zur Mitte der nächsten Woche soll es wieder wärmer und trockener werden.
+
+Die Gastgeber schossen in den letzten zehn Minuten zwei Tore, gewannen das
+Spiel und übernahmen die Tabellenspitze. Ihr Trainer sagte, er sei stolz auf
+die Spieler, die nicht aufgegeben hätten, obwohl sie zur Halbzeit zurücklagen.
+Das nächste Spiel findet auswärts gegen den Meister der letzten Saison statt.
+
+Forscher der Universität haben in den Wäldern im Süden eine neue Froschart
+entdeckt. Das Tier ist kleiner als eine Münze und lebt zwischen den Blättern am
+Boden. Die Wissenschaftler glauben, dass viele andere Arten noch unbekannt sind,
+und fordern, den Wald vor der Abholzung zu schützen.
+
+Meine Großmutter wohnte in einem kleinen Haus am Fluss, wo sie Gemüse anbaute
+und ein paar Hühner hielt. Jeden Sommer besuchten wir sie und verbrachten die
+Tage mit Angeln, Lesen und Spaziergängen über die Felder. Sie wusste immer eine
+Geschichte aus der alten Zeit zu erzählen, als es im Dorf noch keine Autos gab
+und jeder jeden kannte. Ich erinnere mich noch heute an den Duft ihres Brotes.
+
+Das Unternehmen kündigte an, eine neue Fabrik zu bauen, in der mehr als
+zweitausend Menschen arbeiten sollen. Die Produktion soll innerhalb von zwei
+Jahren beginnen. Nach Ansicht von Analysten wächst die Nachfrage nach
+Elektroautos schnell, und die Preise für Batterien sinken. Einige warnten
+jedoch, dass sich der Markt abschwächen könnte, wenn sich die Wirtschaft nicht
+erholt.
+
+Der Stadtrat hat beschlossen, die alte Brücke für Reparaturen zu schließen, die
+bis zu sechs Monate dauern können. Die Busse werden durch die Innenstadt
+umgeleitet, und morgens und abends fahren mehr Züge. Die Ladenbesitzer
+befürchten, dass während der Arbeiten weniger Kunden zu ihnen kommen werden.`,
+
+	"french": `Tous les êtres humains naissent libres et égaux en dignité et en
+droits. Ils sont doués de raison et de conscience et doivent agir les uns
+envers les autres dans un esprit de fraternité. Le français est une langue
+romane parlée en France, en Belgique, en Suisse et au Canada. Le ministère des
+finances a annoncé que le budget de l'année prochaine sera adopté en décembre.
+Les prix du pétrole ont augmenté après la décision des pays producteurs. C'est
+la plus forte hausse depuis le début de l'année. Le gouvernement a promis de
+soutenir les petites et moyennes entreprises, tandis que la banque centrale a
+maintenu ses taux d'intérêt inchangés. Les actions de la société ont fortement
+baissé mercredi après qu'elle a annoncé des bénéfices inférieurs aux attentes
+pour le troisième trimestre.
+
+Le premier ministre a déclaré mardi devant le parlement que la nouvelle loi
+protégerait les salariés qui perdent leur emploi, mais l'opposition estime que
+cela ne suffit pas. Des députés des deux partis ont demandé plus de temps pour
+lire la proposition avant de voter. Plusieurs milliers de personnes se sont
+rassemblées devant le bâtiment pour réclamer des salaires plus élevés et de
+meilleures conditions dans les hôpitaux.
+
+De fortes pluies sont attendues ce week-end dans le nord du pays, avec un vent
+violent sur la côte et de la neige sur les hauteurs. La nuit, les températures
+descendront sous zéro et les automobilistes sont invités à la prudence sur les
+routes. Le temps devrait redevenir plus doux et plus sec au milieu de la
+semaine prochaine.
+
+L'équipe locale a marqué deux buts dans les dix dernières minutes pour gagner
+le match et prendre la tête du championnat. Leur entraîneur s'est dit fier des
+joueurs, qui n'ont jamais abandonné, même lorsqu'ils étaient menés à la
+mi-temps. La prochaine rencontre se jouera à l'extérieur contre le champion de
+la saison dernière.
+
+Des chercheurs de l'université ont découvert une nouvelle espèce de grenouille
+dans les forêts du sud. L'animal est plus petit qu'une pièce de monnaie et vit
+parmi les feuilles sur le sol. Les scientifiques pensent que beaucoup d'autres
+espèces sont encore inconnues et demandent que la forêt soit protégée.
+
+Ma grand-mère habitait une petite maison au bord de la rivière, où elle
+cultivait des légumes et élevait quelques poules. Chaque été, nous allions la
+voir et nous passions nos journées à pêcher, à lire et à nous promener dans les
+champs. Elle avait toujours une histoire à raconter sur l'ancien temps, quand il
+n'y avait pas de voitures au village et que tout le monde se connaissait. Je me
+souviens encore de l'odeur du pain qu'elle faisait.
+
+L'entreprise a annoncé qu'elle allait construire une nouvelle usine qui
+emploiera plus de deux mille personnes. La production devrait commencer d'ici
+deux ans. Selon les analystes, la demande de voitures électriques a augmenté
+rapidement et le prix des batteries a baissé. Certains ont toutefois averti que
+le marché pourrait ralentir si l'économie ne se redresse pas.
+
+Le conseil municipal a décidé de fermer le vieux pont pour des travaux qui
+pourraient durer jusqu'à six mois. Les bus seront déviés par le centre-ville et
+il y aura plus de trains le matin et le soir. Les commerçants craignent que
+moins de clients puissent venir chez eux pendant la durée des travaux.`,
+
+	"spanish": `Todos los seres humanos nacen libres e iguales en dignidad y derechos
+y, dotados como están de razón y conciencia, deben comportarse fraternalmente
+los unos con los otros. El español es una lengua romance que se habla en España
+y en gran parte de América. El ministerio de hacienda anunció que el
+presupuesto del próximo año será aprobado en diciembre. Los precios del
+petróleo subieron después de la decisión de los países productores. Es el mayor
+aumento desde el comienzo del año. El gobierno prometió apoyar a las pequeñas y
+medianas empresas, mientras que el banco central mantuvo sin cambios los tipos
+de interés. Las acciones de la compañía cayeron con fuerza el miércoles después
+de que anunciara unos beneficios del tercer trimestre inferiores a lo esperado.
+
+El primer ministro dijo el martes ante el parlamento que la nueva ley protegerá
+a los trabajadores que pierdan su empleo, pero la oposición considera que no es
+suficiente. Diputados de ambos partidos pidieron más tiempo para leer la
+propuesta antes de votar. Varios miles de personas se reunieron frente al
+edificio para exigir salarios más altos y mejores condiciones en los hospitales.
+
+Se esperan fuertes lluvias este fin de semana en el norte del país, con viento
+intenso en la costa y nieve en las zonas altas. Por la noche las temperaturas
+bajarán de cero y se ha pedido a los conductores que tengan cuidado en las
+carreteras. A mediados de la próxima semana el tiempo será más cálido y seco.
+
+El equipo local marcó dos goles en los últimos diez minutos para ganar el
+partido y ponerse en cabeza de la liga. Su entrenador dijo que estaba orgulloso
+de los jugadores, que nunca se rindieron aunque perdían en el descanso. El
+próximo partido se jugará fuera de casa contra el campeón de la temporada
+pasada.
+
+Investigadores de la universidad han descubierto una nueva especie de rana en
+los bosques del sur. El animal es más pequeño que una moneda y vive entre las
+hojas del suelo. Los científicos creen que muchas otras especies siguen siendo
+desconocidas y piden que se proteja el bosque de la tala.
+
+Mi abuela vivía en una casa pequeña junto al río, donde cultivaba verduras y
+tenía algunas gallinas. Cada verano íbamos a visitarla y pasábamos los días
+pescando, leyendo y paseando por el campo. Siempre tenía una historia que contar
+sobre los viejos tiempos, cuando no había coches en el pueblo y todos se
+conocían. Todavía recuerdo el olor del pan que hacía.
+
+La empresa anunció que construirá una nueva fábrica en la que trabajarán más de
+dos mil personas. Se espera que la producción comience dentro de dos años.
+Según los analistas, la demanda de coches eléctricos ha crecido con rapidez y
+los precios de las baterías han bajado. Sin embargo, algunos advirtieron que el
+mercado podría frenarse si la economía no se recupera.
+
+El ayuntamiento ha decidido cerrar el puente viejo para unas obras que podrían
+durar hasta seis meses. Los autobuses se desviarán por el centro y habrá más
+trenes por la mañana y por la tarde. Los comerciantes temen que, mientras duren
+las obras, lleguen menos clientes a sus tiendas.`,
+}
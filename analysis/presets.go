@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Preset returns the config of the built-in analyser for a language:
+// english, bulgarian, russian, german, french or spanish. They normalise
+// the text to NFKC, split it into Unicode words (which handles Cyrillic),
+// lowercase it, and remove the stop words of the language before stemming.
+//
+// English, Russian, French and Spanish are stemmed by the snowball package.
+// It has no German stemmer, so German uses StemGerman, a port of the
+// Snowball German algorithm in this package, and Bulgarian uses the rule
+// stemmer StemBulgarian, which isn't a Snowball algorithm.
+func Preset(language string) (*Config, error) {
+	stopWords, ok := StopWords[language]
+	if !ok {
+		return nil, fmt.Errorf("unknown analyser %s (available: %s)", language, strings.Join(PresetNames(), ", "))
+	}
+
+	return &Config{
+		CharFilters: []string{"nfkc"},
+		Tokeniser:   "unicode",
+		TokenFilters: []FilterConfig{
+			{Type: "lowercase"},
+			{Type: "stopwords", Words: stopWords},
+			{Type: "stem", Language: language},
+		},
+	}, nil
+}
+
+// MultilingualPreset returns the config of an analyser which detects the
+// language of each text and analyses it with the built-in analyser of that
+// language. Texts in other languages are only split into lowercase words.
+func MultilingualPreset() *Config {
+	config := &Config{
+		CharFilters: []string{"nfkc"},
+		Tokeniser:   "unicode",
+		TokenFilters: []FilterConfig{
+			{Type: "lowercase"},
+		},
+		Languages: make(map[string]*Config),
+	}
+
+	for _, language := range Languages() {
+		config.Languages[language], _ = Preset(language)
+	}
+
+	return config
+}
+
+// PresetNames returns the languages of the built-in analysers, sorted
+func PresetNames() []string {
+	var names []string
+	for name := range StopWords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load returns the built-in analyser config with the given name (a
+// language or multilingual), or reads the config from the file with that
+// name
+func Load(nameOrFile string) (*Config, error) {
+	if _, ok := StopWords[nameOrFile]; ok {
+		return Preset(nameOrFile)
+	}
+	if nameOrFile == "multilingual" {
+		return MultilingualPreset(), nil
+	}
+
+	if _, err := os.Stat(nameOrFile); err != nil {
+		return nil, fmt.Errorf("%s is neither a config file nor a built-in analyser (available: %s, multilingual)", nameOrFile, strings.Join(PresetNames(), ", "))
+	}
+	return ReadConfigFile(nameOrFile)
+}
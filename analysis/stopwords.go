@@ -0,0 +1,115 @@
+package analysis
+
+import (
+	"strings"
+)
+
+// StopWords holds the built-in stop word lists by language. Apart from
+// Bulgarian, they are the Snowball lists.
+var StopWords = map[string][]string{
+	"english":   strings.Fields(englishStopWords),
+	"bulgarian": strings.Fields(bulgarianStopWords),
+	"russian":   strings.Fields(russianStopWords),
+	"german":    strings.Fields(germanStopWords),
+	"french":    strings.Fields(frenchStopWords),
+	"spanish":   strings.Fields(spanishStopWords),
+}
+
+const englishStopWords = `
+i me my myself we our ours ourselves you your yours yourself yourselves he
+him his himself she her hers herself it its itself they them their theirs
+themselves what which who whom this that these those am is are was were be
+been being have has had having do does did doing would should could ought
+a an the and but if or because as until while of at by for with about
+against between into through during before after above below to from up
+down in out on off over under again further then once here there when where
+why how all any both each few more most other some such no nor not only own
+same so than too very s t can will just don now
+`
+
+const bulgarianStopWords = `
+а автентичен аз ако ала бе без беше би бивш бивша бившо бил била били било
+благодаря близо бъдат бъде бяха в вас ваш ваша вероятно вече взема ви вие
+винаги внимава време все всеки всички всичко всяка във въпреки върху г ги
+главен главна главно глас го година години годишен д да дали два двама
+двамата две двете ден днес дни до добра добре добро добър докато докога
+дори досега доста друг друга други е евтин едва един една еднаква еднакви
+еднакъв едно екип ето живот за забавям зад заедно заради засега заспал
+затова защо защото и из или им има имат иска й каза как каква какво както
+какъв като кога когато което които кой който колко която къде където към
+лесен лесно ли лош м май малко ме между мек мен месец ми много мнозина мога
+могат може мокър моля момента му н на над назад най направи напред например
+нас не него нещо нея ни ние никой нито нищо но нов нова нови новина някои
+някой няколко няма обаче около освен особено от отгоре отново още пак по
+повече повечето под поне поради после почти прави пред преди през при пък
+първата първи първо пъти равен равна с са сам само се сега си син скоро
+след следващ сме смях според сред срещу сте съм със също т тази така
+такива такъв там твой те тези ти то това тогава този той толкова точно три
+трябва тук тъй тя тях у утре харесва хиляди ч часа че често чрез ще щом
+юмрук я як
+`
+
+const russianStopWords = `
+и в во не что он на я с со как а то все она так его но да ты к у же вы за
+бы по только ее мне было вот от меня еще нет о из ему теперь когда даже ну
+вдруг ли если уже или ни быть был него до вас нибудь опять уж вам ведь там
+потом себя ничего ей может они тут где есть надо ней для мы тебя их чем
+была сам чтоб без будто чего раз тоже себе под будет ж тогда кто этот того
+потому этого какой совсем ним здесь этом один почти мой тем чтобы нее
+сейчас были куда зачем всех никогда можно при наконец два об другой хоть
+после над больше тот через эти нас про всего них какая много разве три эту
+моя впрочем хорошо свою этой перед иногда лучше чуть том нельзя такой им
+более всегда конечно всю между
+`
+
+const germanStopWords = `
+aber alle allem allen aller alles als also am an ander andere anderem
+anderen anderer anderes anderm andern anderr anders auch auf aus bei bin bis
+bist da damit dann der den des dem die das dass daß derselbe derselben
+denselben desselben demselben dieselbe dieselben dasselbe dazu dein deine
+deinem deinen deiner deines denn derer dessen dich dir du dies diese diesem
+diesen dieser dieses doch dort durch ein eine einem einen einer eines einig
+einige einigem einigen einiger einiges einmal er ihn ihm es etwas euer eure
+eurem euren eurer eures für gegen gewesen hab habe haben hat hatte hatten
+hier hin hinter ich mich mir ihr ihre ihrem ihren ihrer ihres euch im in
+indem ins ist jede jedem jeden jeder jedes jene jenem jenen jener jenes jetzt
+kann kein keine keinem keinen keiner keines können könnte machen man manche
+manchem manchen mancher manches mein meine meinem meinen meiner meines mit
+muss musste nach nicht nichts noch nun nur ob oder ohne sehr sein seine
+seinem seinen seiner seines selbst sich sie ihnen sind so solche solchem
+solchen solcher solches soll sollte sondern sonst über um und uns unsere
+unserem unseren unser unseres unter viel vom von vor während war waren warst
+was weg weil weiter welche welchem welchen welcher welches wenn werde werden
+wie wieder will wir wird wirst wo wollen wollte würde würden zu zum zur zwar
+zwischen
+`
+
+const frenchStopWords = `
+au aux avec ce ces dans de des du elle en et eux il je la le leur lui ma
+mais me même mes moi mon ne nos notre nous on ou par pas pour qu que qui sa
+se ses son sur ta te tes toi ton tu un une vos votre vous c d j l à m n s t
+y été étée étées étés étant suis es est sommes êtes sont serai seras sera
+serons serez seront serais serait serions seriez seraient étais était
+étions étiez étaient fus fut fûmes fûtes furent sois soit soyons soyez
+soient fusse fusses fût fussions fussiez fussent ayant eu eue eues eus ai as
+avons avez ont aurai auras aura aurons aurez auront aurais aurait aurions
+auriez auraient avais avait avions aviez avaient eut eûmes eûtes eurent aie
+aies ait ayons ayez aient eusse eusses eût eussions eussiez eussent ceci
+cela celà cet cette ici ils les leurs quel quels quelle quelles sans soi
+`
+
+const spanishStopWords = `
+de la que el en y a los del se las por un para con no una su al lo como más
+pero sus le ya o este sí porque esta entre cuando muy sin sobre también me
+hasta hay donde quien desde todo nos durante todos uno les ni contra otros
+ese eso ante ellos e esto mí antes algunos qué unos yo otro otras otra él
+tanto esa estos mucho quienes nada muchos cual poco ella estar estas algunas
+algo nosotros mi mis tú te ti tu tus ellas nosotras vosotros vosotras os
+mío mía míos mías tuyo tuya tuyos tuyas suyo suya suyos suyas nuestro nuestra
+nuestros nuestras vuestro vuestra vuestros vuestras esos esas estoy estás
+está estamos estáis están esté estés estemos estéis estén estaré estarás
+estará estaremos estaréis estarán era eras éramos erais eran fui fue fuimos
+fueron soy eres es somos sois son sea seas seamos seáis sean he has ha
+hemos habéis han había habías habíamos habíais habían tengo tienes tiene
+tenemos tenéis tienen
+`
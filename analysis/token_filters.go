@@ -0,0 +1,154 @@
+package analysis
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kljensen/snowball"
+)
+
+// TokenFilter transforms a token, or drops it by returning ""
+type TokenFilter interface {
+	Filter(token string) string
+}
+
+// NewTokenFilter returns the token filter declared by the config. The
+// types are lowercase, stopwords, stem, length and numbers.
+func NewTokenFilter(config *FilterConfig) (TokenFilter, error) {
+	switch config.Type {
+	case "lowercase":
+		return &LowercaseFilter{}, nil
+	case "stopwords":
+		return NewStopWordFilter(config.Words), nil
+	case "stem":
+		return NewStemFilter(config.Language)
+	case "length":
+		return &LengthFilter{Min: config.Min, Max: config.Max}, nil
+	case "numbers":
+		replacement := config.Replacement
+		if replacement == "" {
+			replacement = "#"
+		}
+		return &NumberFilter{Replacement: replacement}, nil
+	default:
+		return nil, fmt.Errorf("unknown token filter %s (available: lowercase, stopwords, stem, length, numbers)", config.Type)
+	}
+}
+
+// LowercaseFilter lowercases tokens
+type LowercaseFilter struct{}
+
+func (l *LowercaseFilter) Filter(token string) string {
+	return strings.ToLower(token)
+}
+
+// StopWordFilter drops the stop words. It is usually placed after the
+// lowercase filter and before the stemmer.
+type StopWordFilter struct {
+	words map[string]struct{}
+}
+
+func NewStopWordFilter(words []string) *StopWordFilter {
+	s := &StopWordFilter{words: make(map[string]struct{}, len(words))}
+	for _, word := range words {
+		s.words[word] = struct{}{}
+	}
+	return s
+}
+
+func (s *StopWordFilter) Filter(token string) string {
+	if _, ok := s.words[token]; ok {
+		return ""
+	}
+	return token
+}
+
+// StemFilter stems tokens with the stemmer of a language: one of the
+// Snowball stemmers, German, or the Bulgarian rule stemmer
+type StemFilter struct {
+	Language string
+	stem     func(string) string
+}
+
+// stemmers which the snowball package doesn't have
+var stemmers = map[string]func(string) string{
+	"german":    StemGerman,
+	"bulgarian": StemBulgarian,
+}
+
+func NewStemFilter(language string) (*StemFilter, error) {
+	if stem, ok := stemmers[language]; ok {
+		return &StemFilter{Language: language, stem: stem}, nil
+	}
+
+	_, err := snowball.Stem("test", language, true)
+	if err != nil {
+		return nil, err
+	}
+	return &StemFilter{
+		Language: language,
+		stem: func(word string) string {
+			stemmed, err := snowball.Stem(word, language, true)
+			if err != nil {
+				return word
+			}
+			return stemmed
+		},
+	}, nil
+}
+
+func (s *StemFilter) Filter(token string) string {
+	return s.stem(token)
+}
+
+// LengthFilter drops tokens shorter than Min or longer than Max characters
+type LengthFilter struct {
+	Min int
+	Max int // unlimited if 0
+}
+
+func (l *LengthFilter) Filter(token string) string {
+	length := utf8.RuneCountInString(token)
+	if length < l.Min || (l.Max > 0 && length > l.Max) {
+		return ""
+	}
+	return token
+}
+
+// NumberFilter replaces all numbers (e.g. 42, -3.5 or 1,000) with the same
+// token, so that they don't each become a separate term
+type NumberFilter struct {
+	Replacement string
+}
+
+var numberRegex = regexp.MustCompile(`^[-+]?[0-9]+([.,][0-9]+)*%?$`)
+
+func (n *NumberFilter) Filter(token string) string {
+	if numberRegex.MatchString(token) {
+		return n.Replacement
+	}
+	return token
+}
+
+func readWordsFile(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file: %s", err)
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+
+	return words, scanner.Err()
+}
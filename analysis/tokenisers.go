@@ -0,0 +1,92 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/DexterLB/prose/tokenize"
+)
+
+// WordTokeniser splits text into tokens
+type WordTokeniser interface {
+	Tokenise(text string) []string
+}
+
+// NewWordTokeniser returns the tokeniser with the given name: treebank (the
+// default), unicode or whitespace
+func NewWordTokeniser(name string) (WordTokeniser, error) {
+	switch name {
+	case "treebank", "":
+		return &TreebankTokeniser{}, nil
+	case "unicode":
+		return &UnicodeTokeniser{}, nil
+	case "whitespace":
+		return &WhitespaceTokeniser{}, nil
+	default:
+		return nil, fmt.Errorf("unknown tokeniser %s (available: treebank, unicode, whitespace)", name)
+	}
+}
+
+// TreebankTokeniser splits English prose into sentences and then into
+// words with the Penn Treebank conventions, like EnglishTokeniser.
+// Punctuation tokens are dropped.
+type TreebankTokeniser struct{}
+
+func (t *TreebankTokeniser) Tokenise(text string) []string {
+	sentenceSplitter, _ := tokenize.NewThreadSafePragmaticSegmenter("en")
+	tokeniser := tokenize.NewTreebankWordTokenizer()
+
+	var tokens []string
+	for _, sentence := range sentenceSplitter.Tokenize(text) {
+		for _, word := range tokeniser.Tokenize(sentence) {
+			if strings.IndexFunc(word, isWordRune) >= 0 {
+				tokens = append(tokens, word)
+			}
+		}
+	}
+
+	return tokens
+}
+
+// UnicodeTokeniser splits text into runs of letters and digits, in any
+// script. Points and commas between digits are kept, so that numbers such
+// as 3.14 stay whole.
+type UnicodeTokeniser struct{}
+
+func (u *UnicodeTokeniser) Tokenise(text string) []string {
+	runes := []rune(text)
+
+	var tokens []string
+	start := -1
+	for i, r := range runes {
+		inNumber := (r == '.' || r == ',') && start >= 0 &&
+			unicode.IsDigit(runes[i-1]) && i+1 < len(runes) && unicode.IsDigit(runes[i+1])
+
+		switch {
+		case isWordRune(r) || inNumber:
+			if start < 0 {
+				start = i
+			}
+		case start >= 0:
+			tokens = append(tokens, string(runes[start:i]))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, string(runes[start:]))
+	}
+
+	return tokens
+}
+
+// WhitespaceTokeniser splits text on whitespace only
+type WhitespaceTokeniser struct{}
+
+func (w *WhitespaceTokeniser) Tokenise(text string) []string {
+	return strings.Fields(text)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+// Config holds flag values by flag name, as written by the tune command
+type Config map[string]interface{}
+
+// applyConfig sets the flags of the command to the values in the file given
+// by the --config flag, unless they were given on the command line. Values
+// for flags which the command doesn't have are ignored, so that the same
+// config can be used with every command.
+func applyConfig(c *cli.Context) error {
+	if c.String("config") == "" {
+		return nil
+	}
+
+	f, err := os.Open(c.String("config"))
+	if err != nil {
+		return fmt.Errorf("unable to open config: %s", err)
+	}
+	defer f.Close()
+
+	// numbers are kept as written, since float64 would print large
+	// integers such as 1000000 as 1e+06, which int flags don't accept
+	decoder := json.NewDecoder(f)
+	decoder.UseNumber()
+
+	var config Config
+	err = decoder.Decode(&config)
+	if err != nil {
+		return fmt.Errorf("unable to parse config: %s", err)
+	}
+
+	// whether each flag can be set from the config
+	flags := make(map[string]bool)
+	for _, flag := range c.Command.Flags {
+		names := strings.Split(flag.GetName(), ",")
+		set := false
+		for i := range names {
+			names[i] = strings.TrimSpace(names[i])
+			set = set || c.IsSet(names[i])
+		}
+		for _, name := range names {
+			flags[name] = !set
+		}
+	}
+
+	for name, value := range config {
+		if !flags[name] {
+			continue
+		}
+
+		err = c.Set(name, fmt.Sprint(value))
+		if err != nil {
+			return fmt.Errorf("invalid value for %s in config: %s", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Save writes the config as JSON to the file
+func (config Config) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("unable to open file: %s", err)
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(config)
+}
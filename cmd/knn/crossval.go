@@ -0,0 +1,98 @@
+package main
+
+import (
+	"log"
+	"os"
+	"runtime"
+
+	"github.com/DexterLB/search/evaluation"
+	"github.com/DexterLB/search/indices"
+	"github.com/DexterLB/search/knn"
+	"github.com/urfave/cli"
+)
+
+var crossvalCommand = cli.Command{
+	Name:   "crossval",
+	Usage:  "perform stratified k-fold cross-validation on an index",
+	Action: crossval,
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:  "input, i",
+			Usage: "File with index",
+			Value: "/tmp/index.gob.gz",
+		},
+		cli.IntFlag{
+			Name:  "folds",
+			Usage: "Number of folds",
+			Value: 10,
+		},
+		cli.Int64Flag{
+			Name:  "seed",
+			Usage: "Seed for assigning documents to folds",
+			Value: 1,
+		},
+		cli.IntFlag{
+			Name:  "k",
+			Usage: "Number of neighbours to consider for classification",
+			Value: 3,
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Format of the report (text or json)",
+			Value: "text",
+		},
+	}, preprocessFlags...),
+}
+
+func crossval(c *cli.Context) {
+	numCPU := runtime.NumCPU()
+
+	options, err := preprocessOptions(c)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ti, err := loadIndex(c, "input")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	folds := c.Int("folds")
+	if folds < 2 || folds > len(ti.Documents) {
+		log.Fatalf("the number of folds must be between 2 and the number of documents (%d)", len(ti.Documents))
+	}
+
+	k := c.Int("k")
+	trainer := func(trainingSet *indices.TotalIndex) func(*knn.DocumentIndex) []int32 {
+		ki := knn.Preprocess(trainingSet, options, numCPU)
+		return func(document *knn.DocumentIndex) []int32 {
+			if ki.HasApproximateSearch() {
+				return ki.ClassifyApproximate(document, k)
+			}
+			return ki.ClassifyForward(document, k, numCPU)
+		}
+	}
+
+	evaluations := knn.CrossValidate(ti, trainer, folds, c.Int64("seed"))
+
+	summaries := make([]*evaluation.Summary, len(evaluations))
+	for i := range evaluations {
+		summaries[i] = evaluations[i].Summary()
+	}
+	stats := evaluation.Statistics(summaries)
+
+	switch c.String("format") {
+	case "text":
+		for i := range summaries {
+			log.Printf("fold %d: %s", i+1, summaries[i])
+		}
+		err = evaluation.WriteStatistics(os.Stdout, stats)
+	case "json":
+		err = evaluation.WriteStatisticsJSON(os.Stdout, stats)
+	default:
+		log.Fatalf("unknown format %s", c.String("format"))
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
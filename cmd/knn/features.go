@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+
+	"github.com/DexterLB/search/featureselection"
+	"github.com/urfave/cli"
+)
+
+var featuresCommand = cli.Command{
+	Name:   "features",
+	Usage:  "show the best feature terms of each class",
+	Action: features,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "input, i",
+			Usage: "File with index",
+			Value: "/tmp/index.gob.gz",
+		},
+		labelSetFlag,
+		cli.StringFlag{
+			Name:  "scorer",
+			Usage: "Feature selection scorer (chi2, mi, ig, or, gss, bns, or df to keep the most frequent terms)",
+			Value: "chi2",
+		},
+		cli.IntFlag{
+			Name:  "features-per-class, f",
+			Usage: "Number of feature terms to show for each class",
+			Value: 20,
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Output format (text, csv or json)",
+			Value: "text",
+		},
+	},
+}
+
+func features(c *cli.Context) {
+	ti, err := loadIndex(c, "input")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	scorer, err := featureselection.ParseScorer(c.String("scorer"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if c.Int("features-per-class") < 0 {
+		log.Fatal("--features-per-class must not be negative")
+	}
+
+	reports := featureselection.Report(ti, scorer, int32(c.Int("features-per-class")), runtime.NumCPU())
+
+	switch c.String("format") {
+	case "text":
+		err = writeFeaturesText(reports, os.Stdout)
+	case "csv":
+		err = writeFeaturesCSV(reports, os.Stdout)
+	case "json":
+		// JSON can't represent infinities, which some scorers produce
+		for i := range reports {
+			for j := range reports[i].Terms {
+				reports[i].Terms[j].Score = finite(reports[i].Terms[j].Score)
+			}
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(reports)
+	default:
+		err = fmt.Errorf("unknown format %s", c.String("format"))
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func finite(x float64) float64 {
+	if math.IsNaN(x) {
+		return 0
+	}
+	return math.Max(-math.MaxFloat64, math.Min(math.MaxFloat64, x))
+}
+
+func writeFeaturesText(reports []featureselection.ClassReport, w io.Writer) error {
+	for _, report := range reports {
+		_, err := fmt.Fprintf(w, "%s (%d documents):\n", report.Class, report.Documents)
+		if err != nil {
+			return err
+		}
+
+		for i, term := range report.Terms {
+			_, err = fmt.Fprintf(
+				w, "  %3d. %-20s score %10.4f  df %6d  class df %6d\n",
+				i+1, term.Term, term.Score, term.DocumentFrequency, term.ClassDocumentFrequency,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeFeaturesCSV(reports []featureselection.ClassReport, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"class", "rank", "term", "score", "df", "class_df"})
+
+	for _, report := range reports {
+		for i, term := range report.Terms {
+			writer.Write([]string{
+				report.Class,
+				strconv.Itoa(i + 1),
+				term.Term,
+				strconv.FormatFloat(term.Score, 'g', -1, 64),
+				strconv.Itoa(int(term.DocumentFrequency)),
+				strconv.Itoa(int(term.ClassDocumentFrequency)),
+			})
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
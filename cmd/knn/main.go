@@ -1,19 +1,109 @@
 package main
 
 import (
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"runtime"
 
 	"github.com/DexterLB/search/documents"
+	"github.com/DexterLB/search/evaluation"
+	"github.com/DexterLB/search/featureselection"
 	"github.com/DexterLB/search/indices"
 	"github.com/DexterLB/search/knn"
+	"github.com/DexterLB/search/lsh"
 	"github.com/DexterLB/search/processing"
 	"github.com/DexterLB/search/serialisation"
 	"github.com/DexterLB/search/utils"
 	"github.com/urfave/cli"
 )
 
+// flags shared by all commands which preprocess an index
+var preprocessFlags = []cli.Flag{
+	labelSetFlag,
+	cli.StringFlag{
+		Name:  "config",
+		Usage: "JSON file with flag values, e.g. as written by tune (flags given on the command line take precedence)",
+		Value: "",
+	},
+	cli.StringFlag{
+		Name:  "scorer",
+		Usage: "Feature selection scorer (chi2, mi, ig, or, gss, bns, or df to keep the most frequent terms)",
+		Value: "chi2",
+	},
+	cli.StringFlag{
+		Name:  "aggregation",
+		Usage: "How to combine per-class scores: round-robin (uses --features-per-class), max or avg (use --features)",
+		Value: "round-robin",
+	},
+	cli.IntFlag{
+		Name:  "features-per-class, f",
+		Usage: "Number of feature terms to select for each class",
+		Value: 20,
+	},
+	cli.IntFlag{
+		Name:  "features",
+		Usage: "Total number of feature terms to select with a global aggregation",
+		Value: 1000,
+	},
+	cli.IntFlag{
+		Name:  "min-df",
+		Usage: "Never select terms which are in fewer training documents than this, with any scorer",
+		Value: 1,
+	},
+	cli.StringFlag{
+		Name:  "metric, m",
+		Usage: "Distance metric (euclidean, cosine, manhattan, jaccard or bm25)",
+		Value: "euclidean",
+	},
+	cli.StringFlag{
+		Name:  "weighting",
+		Usage: "Term weighting for euclidean, cosine and manhattan (tfidf, log-tfidf, tf or binary)",
+		Value: "tfidf",
+	},
+	cli.BoolFlag{
+		Name:  "hnsw",
+		Usage: "Build an HNSW graph for approximate nearest neighbour search",
+	},
+	cli.IntFlag{
+		Name:  "hnsw-m",
+		Usage: "Number of neighbours per HNSW graph node",
+		Value: knn.DefaultHNSWParams().M,
+	},
+	cli.IntFlag{
+		Name:  "ef-construction",
+		Usage: "Size of the candidate list when building the HNSW graph",
+		Value: knn.DefaultHNSWParams().EfConstruction,
+	},
+	cli.IntFlag{
+		Name:  "ef-search",
+		Usage: "Size of the candidate list when searching the HNSW graph",
+		Value: knn.DefaultHNSWParams().EfSearch,
+	},
+	cli.StringFlag{
+		Name:  "lsh",
+		Usage: "Build an LSH index for approximate nearest neighbour search (simhash or minhash)",
+		Value: "",
+	},
+	cli.IntFlag{
+		Name:  "lsh-bands",
+		Usage: "Number of LSH bands (more bands find more candidates)",
+		Value: lsh.DefaultParams().Bands,
+	},
+	cli.IntFlag{
+		Name:  "lsh-rows",
+		Usage: "Number of hashes per LSH band (more rows find fewer candidates)",
+		Value: lsh.DefaultParams().Rows,
+	},
+}
+
+var labelSetFlag = cli.StringFlag{
+	Name:  "label-set",
+	Usage: "Label set to use as classes (topics, places, people, orgs or exchanges), instead of the classes of the index",
+	Value: "",
+}
+
 func main() {
 	app := cli.NewApp()
 	app.Name = "knn"
@@ -24,7 +114,7 @@ func main() {
 			Name:   "preprocess",
 			Usage:  "preprocess an index to create a KNN Info file",
 			Action: preprocess,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "input, i",
 					Usage: "File with index",
@@ -35,12 +125,7 @@ func main() {
 					Usage: "Preprocessed data",
 					Value: "/tmp/knn.gob.gz",
 				},
-				cli.IntFlag{
-					Name:  "features-per-class, f",
-					Usage: "Number of feature terms to select for each class",
-					Value: 20,
-				},
-			},
+			}, preprocessFlags...),
 		},
 		{
 			Name:   "classify-reuters",
@@ -73,7 +158,7 @@ func main() {
 			Name:   "test",
 			Usage:  "perform a test with a split index",
 			Action: test,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				cli.StringFlag{
 					Name:  "training-set",
 					Usage: "Training set index",
@@ -89,13 +174,39 @@ func main() {
 					Usage: "Number of neighbours to consider for classification",
 					Value: 3,
 				},
+				cli.BoolFlag{
+					Name:  "log-documents",
+					Usage: "Log the actual and predicted classes of every document",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Usage: "Format of the evaluation report (text, json or none)",
+					Value: "text",
+				},
+				cli.BoolFlag{
+					Name:  "confusion",
+					Usage: "Show a confusion heatmap and the most confused classes",
+				},
 				cli.IntFlag{
-					Name:  "features-per-class, f",
-					Usage: "Number of feature terms to select for each class",
+					Name:  "heatmap-classes",
+					Usage: "Number of most frequent classes to show in the confusion heatmap",
 					Value: 20,
 				},
-			},
+				cli.IntFlag{
+					Name:  "confused-pairs",
+					Usage: "Number of most confused class pairs to show",
+					Value: 10,
+				},
+				cli.StringFlag{
+					Name:  "confusion-csv",
+					Usage: "Write the full confusion matrix as CSV to this file",
+					Value: "",
+				},
+			}, preprocessFlags...),
 		},
+		featuresCommand,
+		crossvalCommand,
+		tuneCommand,
 	}
 
 	app.Run(os.Args)
@@ -103,25 +214,33 @@ func main() {
 
 func test(c *cli.Context) {
 	numCPU := runtime.NumCPU()
-	trainingSet := indices.NewTotalIndex()
-	err := trainingSet.DeserialiseFromFile(c.String("training-set"))
+
+	options, err := preprocessOptions(c)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	trainingSet, err := loadIndex(c, "training-set")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	testSet := indices.NewTotalIndex()
-	err = testSet.DeserialiseFromFile(c.String("test-set"))
+	testSet, err := loadIndex(c, "test-set")
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	log.Printf("begin preprocessing")
-	ki := knn.Preprocess(trainingSet, int32(c.Int("features-per-class")), numCPU)
+	ki := knn.Preprocess(trainingSet, options, numCPU)
 	log.Printf("end preprocessing")
 
 	k := c.Int("k")
 
 	classifier := func(document *knn.DocumentIndex) []int32 {
+		if ki.HasApproximateSearch() {
+			return ki.ClassifyApproximate(document, k)
+		}
+
 		forward := ki.ClassifyForward(document, k, numCPU)
 		// inverse := ki.ClassifyInverse(document, k)
 
@@ -138,7 +257,157 @@ func test(c *cli.Context) {
 		return forward
 	}
 
-	knn.InteractiveTest(classifier, testSet)
+	result := knn.InteractiveTest(classifier, testSet, c.Bool("log-documents"))
+
+	if ki.HasApproximateSearch() {
+		// measured separately, so that the exact search isn't counted in
+		// the classification time above
+		log.Printf("approximate search: %s", knn.MeasureRecall(ki, testSet, k, numCPU))
+	}
+
+	err = writeSummary(result.Summary(), c.String("format"), os.Stdout)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = writeConfusion(result.Confusion, c)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loadIndex reads the index from the file given by the flag, and selects
+// the label set given by --label-set, if any
+func loadIndex(c *cli.Context, flag string) (*indices.TotalIndex, error) {
+	ti := indices.NewTotalIndex()
+	err := ti.DeserialiseFromFile(c.String(flag))
+	if err != nil {
+		return nil, err
+	}
+
+	if c.String("label-set") != "" {
+		err = ti.SelectLabelSet(c.String("label-set"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", c.String(flag), err)
+		}
+	}
+
+	return ti, nil
+}
+
+func writeConfusion(confusion *evaluation.ConfusionMatrix, c *cli.Context) error {
+	if c.Bool("confusion") {
+		fmt.Printf("\nconfusion heatmap (rows: actual, columns: predicted):\n")
+		err := confusion.WriteHeatmap(os.Stdout, c.Int("heatmap-classes"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("\nmost confused classes (actual -> predicted):\n")
+		err = confusion.WriteConfusions(os.Stdout, c.Int("confused-pairs"))
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.String("confusion-csv") != "" {
+		f, err := os.Create(c.String("confusion-csv"))
+		if err != nil {
+			return fmt.Errorf("unable to open file: %s", err)
+		}
+		defer f.Close()
+
+		return confusion.WriteCSV(f)
+	}
+
+	return nil
+}
+
+func writeSummary(summary *evaluation.Summary, format string, w io.Writer) error {
+	switch format {
+	case "text":
+		return summary.WriteTable(w)
+	case "json":
+		return summary.WriteJSON(w)
+	case "none":
+		return nil
+	default:
+		return fmt.Errorf("unknown format %s", format)
+	}
+}
+
+// preprocessOptions applies the config file, if any, and parses the
+// preprocessing flags
+func preprocessOptions(c *cli.Context) (*knn.PreprocessOptions, error) {
+	err := applyConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	metric, err := knn.ParseMetric(c.String("metric"))
+	if err != nil {
+		return nil, err
+	}
+
+	weighting, err := knn.ParseWeighting(c.String("weighting"))
+	if err != nil {
+		return nil, err
+	}
+
+	scorer, err := featureselection.ParseScorer(c.String("scorer"))
+	if err != nil {
+		return nil, err
+	}
+
+	aggregation, err := featureselection.ParseAggregation(c.String("aggregation"))
+	if err != nil {
+		return nil, err
+	}
+
+	options := &knn.PreprocessOptions{
+		Scorer:        scorer,
+		Aggregation:   aggregation,
+		TermsPerClass: int32(c.Int("features-per-class")),
+		TotalTerms:    int32(c.Int("features")),
+		MinDocuments:  c.Int("min-df"),
+		Metric:        metric,
+		Weighting:     weighting,
+	}
+
+	if c.Bool("hnsw") {
+		params := knn.DefaultHNSWParams()
+		params.M = c.Int("hnsw-m")
+		params.EfConstruction = c.Int("ef-construction")
+		params.EfSearch = c.Int("ef-search")
+		err = params.Validate()
+		if err != nil {
+			return nil, err
+		}
+		options.Graph = &params
+	}
+
+	if c.String("lsh") != "" {
+		if options.Graph != nil {
+			return nil, fmt.Errorf("--hnsw and --lsh can't be used together")
+		}
+
+		kind, err := lsh.ParseKind(c.String("lsh"))
+		if err != nil {
+			return nil, err
+		}
+
+		params := lsh.DefaultParams()
+		params.Kind = kind
+		params.Bands = c.Int("lsh-bands")
+		params.Rows = c.Int("lsh-rows")
+		err = params.Validate()
+		if err != nil {
+			return nil, err
+		}
+		options.Hashing = &params
+	}
+
+	return options, nil
 }
 
 func classifyReuters(c *cli.Context) {
@@ -148,9 +417,14 @@ func classifyReuters(c *cli.Context) {
 		log.Fatal(err)
 	}
 
-	tokeniser, err := processing.NewEnglishTokeniserFromFile(c.String("stopwords"))
+	if ki.Vectors == nil {
+		log.Printf("data file has no precomputed vectors, computing them")
+		ki.ComputeVectors()
+	}
+
+	tokeniser, err := processing.TokeniserForIndex(ki.Index, c.String("stopwords"))
 	if err != nil {
-		log.Fatal("unable to get stopwords: %s", err)
+		log.Fatalf("unable to get tokeniser: %s", err)
 	}
 
 	files := make(chan string, 1)
@@ -167,15 +441,14 @@ func classifyReuters(c *cli.Context) {
 	}()
 
 	go func() {
-		utils.Parallel(func() {
-			processing.CountInDocuments(
-				docs,
-				tokeniser,
-				infosAndTerms,
-				true,
-				true,
-			)
-		}, runtime.NumCPU())
+		processing.CountInDocumentsInOrder(
+			docs,
+			tokeniser,
+			infosAndTerms,
+			true,
+			true,
+			runtime.NumCPU(),
+		)
 		close(infosAndTerms)
 	}()
 
@@ -186,13 +459,17 @@ func classifyReuters(c *cli.Context) {
 }
 
 func preprocess(c *cli.Context) {
-	ti := indices.NewTotalIndex()
-	err := ti.DeserialiseFromFile(c.String("input"))
+	options, err := preprocessOptions(c)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ti, err := loadIndex(c, "input")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	ki := knn.Preprocess(ti, int32(c.Int("features-per-class")), runtime.NumCPU())
+	ki := knn.Preprocess(ti, options, runtime.NumCPU())
 
 	err = serialisation.SerialiseToFile(ki, c.String("output"))
 	if err != nil {
@@ -208,7 +485,12 @@ func interactiveClassify(ki *knn.KNNInfo, ti *indices.TotalIndex, k int) {
 			Length:      ti.Documents[docID].Length,
 		}
 
-		classes := ki.ClassifyForward(docIndex, k, runtime.NumCPU())
+		var classes []int32
+		if ki.HasApproximateSearch() {
+			classes = ki.ClassifyApproximate(docIndex, k)
+		} else {
+			classes = ki.ClassifyForward(docIndex, k, runtime.NumCPU())
+		}
 		log.Printf("document %s\n  --> %s", ti.Documents[docID].Name, ti.StringifyClasses(classes))
 	}
 }
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/DexterLB/search/featureselection"
+	"github.com/DexterLB/search/knn"
+	"github.com/urfave/cli"
+)
+
+var tuneCommand = cli.Command{
+	Name:   "tune",
+	Usage:  "find the best kNN hyperparameters with a grid search",
+	Action: tune,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "training-set",
+			Usage: "Training set index",
+			Value: "/tmp/index.gob.gz",
+		},
+		cli.StringFlag{
+			Name:  "test-set",
+			Usage: "Test (validation) set index",
+			Value: "/tmp/index_test.gob.gz",
+		},
+		labelSetFlag,
+		cli.StringFlag{
+			Name:  "scorer",
+			Usage: "Feature selection scorer (chi2, mi, ig, or, gss, bns, or df to keep the most frequent terms)",
+			Value: "chi2",
+		},
+		cli.StringFlag{
+			Name:  "k",
+			Usage: "Comma separated numbers of neighbours to try",
+			Value: "1,3,5,10,20",
+		},
+		cli.StringFlag{
+			Name:  "features-per-class, f",
+			Usage: "Comma separated numbers of feature terms per class to try",
+			Value: "10,20,50,100",
+		},
+		cli.StringFlag{
+			Name:  "metric, m",
+			Usage: "Comma separated distance metrics to try",
+			Value: "euclidean,cosine",
+		},
+		cli.StringFlag{
+			Name:  "weighting",
+			Usage: "Comma separated term weightings to try",
+			Value: "tfidf,log-tfidf",
+		},
+		cli.IntFlag{
+			Name:  "top",
+			Usage: "Number of configurations to show in the leaderboard",
+			Value: 20,
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Format of the leaderboard (text or json)",
+			Value: "text",
+		},
+		cli.StringFlag{
+			Name:  "output, o",
+			Usage: "Write the best configuration to this file, for use with --config",
+			Value: "/tmp/knn-best.json",
+		},
+	},
+}
+
+func tune(c *cli.Context) {
+	numCPU := runtime.NumCPU()
+
+	grid, err := tuneGrid(c)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	scorer, err := featureselection.ParseScorer(c.String("scorer"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	trainingSet, err := loadIndex(c, "training-set")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	testSet, err := loadIndex(c, "test-set")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	results := knn.Tune(trainingSet, testSet, scorer, grid, numCPU)
+	if len(results) == 0 {
+		log.Fatal("the grid is empty")
+	}
+
+	leaderboard := results
+	if c.Int("top") < len(leaderboard) {
+		leaderboard = leaderboard[:c.Int("top")]
+	}
+
+	switch c.String("format") {
+	case "text":
+		err = writeLeaderboard(os.Stdout, leaderboard)
+	case "json":
+		err = writeLeaderboardJSON(os.Stdout, leaderboard)
+	default:
+		err = fmt.Errorf("unknown format %s", c.String("format"))
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if c.String("output") != "" {
+		best := results[0]
+		config := Config{
+			"scorer":             c.String("scorer"),
+			"aggregation":        featureselection.RoundRobin.String(),
+			"k":                  best.K,
+			"features-per-class": best.TermsPerClass,
+			"metric":             best.Metric.String(),
+			"weighting":          best.Weighting.String(),
+		}
+		if c.String("label-set") != "" {
+			config["label-set"] = c.String("label-set")
+		}
+
+		err = config.Save(c.String("output"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("wrote the best configuration to %s", c.String("output"))
+	}
+}
+
+func tuneGrid(c *cli.Context) (*knn.TuneGrid, error) {
+	grid := &knn.TuneGrid{}
+
+	for _, value := range splitList(c.String("k")) {
+		k, err := strconv.Atoi(value)
+		if err != nil || k < 1 {
+			return nil, fmt.Errorf("invalid k: %s", value)
+		}
+		grid.Ks = append(grid.Ks, k)
+	}
+
+	for _, value := range splitList(c.String("features-per-class")) {
+		termsPerClass, err := strconv.Atoi(value)
+		if err != nil || termsPerClass < 1 {
+			return nil, fmt.Errorf("invalid number of features per class: %s", value)
+		}
+		grid.TermsPerClass = append(grid.TermsPerClass, int32(termsPerClass))
+	}
+
+	for _, value := range splitList(c.String("metric")) {
+		metric, err := knn.ParseMetric(value)
+		if err != nil {
+			return nil, err
+		}
+		grid.Metrics = append(grid.Metrics, metric)
+	}
+
+	for _, value := range splitList(c.String("weighting")) {
+		weighting, err := knn.ParseWeighting(value)
+		if err != nil {
+			return nil, err
+		}
+		grid.Weightings = append(grid.Weightings, weighting)
+	}
+
+	return grid, nil
+}
+
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func writeLeaderboard(w io.Writer, results []*knn.TuneResult) error {
+	_, err := fmt.Fprintf(w, "%4s %4s %9s %-10s %-10s %9s %9s %9s\n",
+		"rank", "k", "features", "metric", "weighting", "micro F1", "macro F1", "accuracy")
+	if err != nil {
+		return err
+	}
+
+	for i, result := range results {
+		_, err = fmt.Fprintf(w, "%4d %4d %9d %-10s %-10s %9.4f %9.4f %9.4f\n",
+			i+1, result.K, result.TermsPerClass, result.Metric, result.Weighting,
+			result.Summary.MicroF1, result.Summary.MacroF1, result.Summary.Accuracy)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type leaderboardEntry struct {
+	K                int     `json:"k"`
+	FeaturesPerClass int32   `json:"features_per_class"`
+	Metric           string  `json:"metric"`
+	Weighting        string  `json:"weighting"`
+	MicroF1          float64 `json:"micro_f1"`
+	MacroF1          float64 `json:"macro_f1"`
+	Accuracy         float64 `json:"accuracy"`
+	SubsetAccuracy   float64 `json:"subset_accuracy"`
+}
+
+func writeLeaderboardJSON(w io.Writer, results []*knn.TuneResult) error {
+	entries := make([]leaderboardEntry, len(results))
+	for i, result := range results {
+		entries[i] = leaderboardEntry{
+			K:                result.K,
+			FeaturesPerClass: result.TermsPerClass,
+			Metric:           result.Metric.String(),
+			Weighting:        result.Weighting.String(),
+			MicroF1:          result.Summary.MicroF1,
+			MacroF1:          result.Summary.MacroF1,
+			Accuracy:         result.Summary.Accuracy,
+			SubsetAccuracy:   result.Summary.SubsetAccuracy,
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
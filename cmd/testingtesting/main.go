@@ -1,11 +1,15 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 
+	"github.com/DexterLB/search/analysis"
 	"github.com/DexterLB/search/documents"
 	"github.com/DexterLB/search/indices"
 	"github.com/DexterLB/search/processing"
@@ -16,13 +20,67 @@ import (
 func main() {
 	app := cli.NewApp()
 	app.Name = "testingtesting"
-	app.Usage = "Parse Reuters XML documents and index them"
+	app.Usage = "Parse documents (Reuters XML by default) and index them"
 	app.Flags = []cli.Flag{
 		cli.StringFlag{
 			Name:  "xmldir, d",
 			Usage: "Directory with Reuters XML files",
 			Value: ".",
 		},
+		cli.StringFlag{
+			Name:  "format, f",
+			Usage: "Format of the documents: xml (Reuters XML files in xmldir), sgml (original Reuters .sgm files in xmldir), txt (directory tree of .txt files, classified by directory), 20ng (20 Newsgroups directory), rcv1 (RCV1-v2 directory), html (directory tree of HTML files, classified by directory), email (directory tree of .eml and mbox files, classified by folder), wikipedia (pages-articles.xml dump, optionally .bz2, classified by category), jsonl or csv",
+			Value: "xml",
+		},
+		cli.StringFlag{
+			Name:  "input, i",
+			Usage: "Directory (for txt, 20ng, rcv1, html and email) or file (for wikipedia, jsonl and csv) with documents",
+			Value: "",
+		},
+		cli.BoolFlag{
+			Name:  "remove-quotes",
+			Usage: "Remove quoted replies from 20 Newsgroups posts",
+		},
+		cli.StringFlag{
+			Name:  "rcv1-hierarchy",
+			Usage: "RCV1 topic hierarchy file (rcv1.topics.hier.orig), to add the ancestors of each topic to the classes",
+			Value: "",
+		},
+		cli.StringFlag{
+			Name:  "class-header",
+			Usage: "Email header to use as class (e.g. List-Id) instead of the folder",
+			Value: "",
+		},
+		cli.StringFlag{
+			Name:  "csv-columns",
+			Usage: "Names of the CSV columns of document fields which aren't named like the fields, e.g. title=headline,body=text",
+			Value: "",
+		},
+		cli.StringFlag{
+			Name:  "csv-class-separator",
+			Usage: "Separator of multiple classes in the CSV classes column",
+			Value: ";",
+		},
+		cli.StringFlag{
+			Name:  "analyser, a",
+			Usage: "Analyser to use instead of the English tokeniser: a built-in one (english, bulgarian, russian, german, french or spanish), or a JSON config of char filters, tokeniser and token filters. It is recorded in the index.",
+			Value: "",
+		},
+		cli.StringFlag{
+			Name:  "language",
+			Usage: "Only index documents in this language (one of: " + strings.Join(analysis.Languages(), ", ") + "), as detected by their text",
+			Value: "",
+		},
+		cli.IntFlag{
+			Name:  "shingles",
+			Usage: "Also index word n-grams (shingles) of up to this many words, e.g. \"crude oil\" for 2",
+			Value: 1,
+		},
+		cli.IntFlag{
+			Name:  "shingle-min-docs",
+			Usage: "Drop shingles which are in fewer training documents than this",
+			Value: 2,
+		},
 		cli.StringFlag{
 			Name:  "stopwords, s",
 			Usage: "Stopwords file. If not specified, defaults to ${xmldir}/stopwords",
@@ -50,6 +108,26 @@ func main() {
 			Name:  "split-size",
 			Usage: "Size of first batch of documents (second will be filled with the rest)",
 		},
+		cli.StringFlag{
+			Name:  "split-mode",
+			Usage: "Split into training (output) and test (--split) indices by a standard Reuters split (modapte, modlewis or modhayes), or with --split-ratio of the documents in the test index: random, stratified (by class) or date (the newest documents)",
+			Value: "",
+		},
+		cli.Float64Flag{
+			Name:  "split-ratio",
+			Usage: "Fraction of the documents in the test index for the random, stratified and date split modes",
+			Value: 0.2,
+		},
+		cli.Int64Flag{
+			Name:  "split-seed",
+			Usage: "Random seed for the random and stratified split modes",
+			Value: 1,
+		},
+		cli.StringFlag{
+			Name:  "split-date",
+			Usage: "Cutoff date for the date split mode (e.g. 1987-04-01): documents from this date on go to the test index, instead of using --split-ratio",
+			Value: "",
+		},
 	}
 
 	app.Action = mainCommand
@@ -58,62 +136,91 @@ func main() {
 }
 
 func mainCommand(c *cli.Context) {
-	files := make(chan string, 200)
-	docs := make(chan *documents.Document, 2000)
-	infosAndTerms := make(chan *indices.InfoAndTerms, 2000)
+	read := make(chan *documents.Document, 2000)
+	var docs <-chan *documents.Document = read
 
-	stopWordsFile := c.String("stopwords")
-	if stopWordsFile == "" {
-		stopWordsFile = filepath.Join(c.String("xmldir"), "stopwords")
+	source, err := documentSource(c)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	tokeniser, err := processing.NewEnglishTokeniserFromFile(stopWordsFile)
+	tokeniser, analyserConfig, err := tokeniser(c)
 	if err != nil {
-		log.Fatal("unable to get stopwords: %s", err)
+		log.Fatal(err)
 	}
 
 	go func() {
-		GetXMLs(c.String("xmldir"), files)
-		close(files)
-	}()
-
-	go func() {
-		utils.Parallel(func() {
-			documents.NewReutersParser().ParseFiles(files, docs)
-		}, runtime.NumCPU())
-		close(docs)
+		err := source.Read(read)
+		if err != nil {
+			log.Fatalf("unable to read documents: %s", err)
+		}
+		close(read)
 	}()
 
-	go func() {
-		utils.Parallel(func() {
-			processing.CountInDocuments(
-				docs,
-				tokeniser,
-				infosAndTerms,
-				c.Bool("classless"),
-				c.Bool("classy"),
-			)
-		}, runtime.NumCPU())
-		close(infosAndTerms)
-	}()
+	if c.String("language") != "" {
+		docs = inLanguage(docs, c.String("language"))
+	}
 
 	index1 := indices.NewTotalIndex()
+	err = processing.RecordAnalyser(index1, analyserConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	if c.String("split") != "" {
-		index1.AddUpTo(infosAndTerms, c.Int("split-size"))
+	if c.Int("shingles") > 1 {
+		tokeniser = &processing.ShingleTokeniser{Tokeniser: tokeniser, MaxSize: c.Int("shingles")}
+		index1.ShingleSize = int32(c.Int("shingles"))
+	}
+	var testInfosAndTerms <-chan *indices.InfoAndTerms
+
+	if c.String("split-mode") != "" {
+		if c.String("split") == "" {
+			log.Fatal("--split-mode needs a file for the test index in --split")
+		}
+
+		split, err := documentSplitter(c)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		trainingDocs := make(chan *documents.Document, 2000)
+		testDocs := make(chan *documents.Document, 2000)
+		go func() {
+			split(docs, trainingDocs, testDocs)
+			close(trainingDocs)
+			close(testDocs)
+		}()
+
+		// the test documents are kept until the training index is done,
+		// so that both can share its dictionary
+		testInfosAndTerms = collect(count(testDocs, tokeniser, c))
+		index1.AddMany(count(trainingDocs, tokeniser, c))
 	} else {
-		index1.AddMany(infosAndTerms)
+		infosAndTerms := count(docs, tokeniser, c)
+		testInfosAndTerms = infosAndTerms
+
+		if c.String("split") != "" {
+			index1.AddUpTo(infosAndTerms, c.Int("split-size"))
+		} else {
+			index1.AddMany(infosAndTerms)
+		}
 	}
 
+	var index2 *indices.TotalIndex
 	if c.String("split") != "" {
-		index2 := indices.NewOffsetTotalIndex(index1)
-		index2.AddMany(infosAndTerms)
+		index2 = indices.NewOffsetTotalIndex(index1)
+		index2.AddMany(testInfosAndTerms)
 		index2.Verify()
 
 		index1.ExtendInverse(len(index2.Inverse.PostingLists))
+	}
 
-		index2.Dictionary.Closed = true
-		index2.ClassNames.Closed = true
+	if c.Int("shingles") > 1 && c.Int("shingle-min-docs") > 1 {
+		index1, index2 = pruneShingles(index1, index2, c.Int("shingle-min-docs"))
+	}
+
+	if index2 != nil {
+		index2.Close()
 		err = index2.SerialiseToFile(c.String("split"))
 		if err != nil {
 			log.Fatalf("Unable to serialise index: %s", err)
@@ -122,21 +229,267 @@ func mainCommand(c *cli.Context) {
 
 	index1.Verify()
 
-	index1.Dictionary.Closed = true
-	index1.ClassNames.Closed = true
+	index1.Close()
 	err = index1.SerialiseToFile(c.String("output"))
 	if err != nil {
 		log.Fatalf("Unable to serialise index: %s", err)
 	}
 }
 
-func GetXMLs(folder string, into chan<- string) {
-	files, err := filepath.Glob(filepath.Join(folder, "*.xml"))
+// pruneShingles drops the shingles which are in fewer than minDocs
+// documents of the first index from both indices (the second may be nil)
+func pruneShingles(index1 *indices.TotalIndex, index2 *indices.TotalIndex, minDocs int) (*indices.TotalIndex, *indices.TotalIndex) {
+	all := []*indices.TotalIndex{index1}
+	if index2 != nil {
+		all = append(all, index2)
+	}
+
+	before := index1.Dictionary.Size
+	pruned := indices.PruneTerms(func(term []byte, documentFrequency int) bool {
+		return documentFrequency >= minDocs || !processing.IsShingle(term)
+	}, all...)
+	log.Printf("pruned the dictionary from %d to %d terms", before, pruned[0].Dictionary.Size)
+
+	if index2 != nil {
+		return pruned[0], pruned[1]
+	}
+	return pruned[0], nil
+}
+
+// count processes the documents into terms and counts in parallel, keeping
+// their order
+func count(docs <-chan *documents.Document, tokeniser processing.Tokeniser, c *cli.Context) <-chan *indices.InfoAndTerms {
+	infosAndTerms := make(chan *indices.InfoAndTerms, 2000)
+
+	go func() {
+		processing.CountInDocumentsInOrder(
+			docs,
+			tokeniser,
+			infosAndTerms,
+			c.Bool("classless"),
+			c.Bool("classy"),
+			runtime.NumCPU(),
+		)
+		close(infosAndTerms)
+	}()
+
+	return infosAndTerms
+}
+
+// inLanguage detects the language of the documents in parallel, and only
+// keeps those in the given one
+func inLanguage(docs <-chan *documents.Document, language string) <-chan *documents.Document {
+	kept := make(chan *documents.Document, 2000)
+
+	jobs := make(chan func() func())
+	go func() {
+		for doc := range docs {
+			doc := doc
+			jobs <- func() func() {
+				if doc.Language == "" {
+					doc.Language = analysis.DetectLanguage(doc.Title + "\n" + doc.Body)
+				}
+				if doc.Language != language {
+					return nil
+				}
+				return func() {
+					kept <- doc
+				}
+			}
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		utils.ParallelOrdered(jobs, runtime.NumCPU())
+		close(kept)
+	}()
+
+	return kept
+}
+
+// collect reads all of the channel in the background, and replays it to the
+// returned channel once it is closed
+func collect(infosAndTerms <-chan *indices.InfoAndTerms) <-chan *indices.InfoAndTerms {
+	replay := make(chan *indices.InfoAndTerms)
+
+	go func() {
+		var all []*indices.InfoAndTerms
+		for it := range infosAndTerms {
+			all = append(all, it)
+		}
+
+		for _, it := range all {
+			replay <- it
+		}
+		close(replay)
+	}()
+
+	return replay
+}
+
+// tokeniser returns the analyser given by --analyser, or an
+// EnglishTokeniser with the stop words file if there is none. The config is
+// nil for the latter.
+func tokeniser(c *cli.Context) (processing.Tokeniser, *analysis.Config, error) {
+	if c.String("analyser") != "" {
+		config, err := analysis.Load(c.String("analyser"))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		analyser, err := analysis.New(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		return analyser, config, nil
+	}
+
+	stopWordsFile := c.String("stopwords")
+	if stopWordsFile == "" {
+		stopWordsFile = filepath.Join(c.String("xmldir"), "stopwords")
+	}
+
+	tokeniser, err := processing.NewEnglishTokeniserFromFile(stopWordsFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to get stopwords: %s", err)
+	}
+	return tokeniser, nil, nil
+}
+
+// documentSplitter returns a function which sends the documents to the
+// training and test channels, by a standard Reuters split or by a split
+// method with a ratio
+func documentSplitter(c *cli.Context) (func(docs <-chan *documents.Document, training chan<- *documents.Document, test chan<- *documents.Document), error) {
+	mode, err := documents.ParseSplitMode(c.String("split-mode"))
+	if err == nil {
+		return mode.SplitDocuments, nil
+	}
+
+	method, err := documents.ParseSplitMethod(c.String("split-mode"))
 	if err != nil {
-		log.Fatal("unable to get files in folder %s: %s", os.Args[1], err)
+		return nil, fmt.Errorf("unknown split mode %s (available: modapte, modlewis, modhayes, random, stratified, date)", c.String("split-mode"))
 	}
 
-	for i := range files {
-		into <- files[i]
+	ratio := c.Float64("split-ratio")
+	if ratio <= 0 || ratio >= 1 {
+		return nil, fmt.Errorf("--split-ratio must be between 0 and 1")
+	}
+
+	var cutoff time.Time
+	if c.String("split-date") != "" {
+		if method != documents.DateSplit {
+			return nil, fmt.Errorf("--split-date only works with the date split mode")
+		}
+
+		cutoff, err = documents.ParseDate(c.String("split-date"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func(docs <-chan *documents.Document, training chan<- *documents.Document, test chan<- *documents.Document) {
+		// the whole corpus is needed to pick the test documents, so only
+		// keep those which will be indexed
+		var all []*documents.Document
+		for doc := range docs {
+			if (len(doc.Classes) >= 1 && c.Bool("classy")) || (len(doc.Classes) == 0 && c.Bool("classless")) {
+				all = append(all, doc)
+			}
+		}
+
+		var assignment []documents.Subset
+		if cutoff.IsZero() {
+			assignment = method.Split(all, ratio, c.Int64("split-seed"))
+		} else {
+			assignment = documents.SplitByDate(all, cutoff)
+		}
+
+		unused := 0
+		for i, doc := range all {
+			switch assignment[i] {
+			case documents.Training:
+				training <- doc
+			case documents.Test:
+				test <- doc
+			default:
+				unused += 1
+			}
+		}
+		if unused > 0 {
+			log.Printf("%d documents without a date were left out of the split", unused)
+		}
+	}, nil
+}
+
+func documentSource(c *cli.Context) (documents.Source, error) {
+	switch c.String("format") {
+	case "xml", "sgml":
+		return &documents.ReutersSource{
+			Dir:             c.String("xmldir"),
+			SGML:            c.String("format") == "sgml",
+			ParallelWorkers: runtime.NumCPU(),
+		}, nil
+	}
+
+	if c.String("input") == "" {
+		return nil, fmt.Errorf("the %s format needs --input", c.String("format"))
+	}
+
+	switch c.String("format") {
+	case "txt":
+		return &documents.TextSource{
+			Dir:             c.String("input"),
+			ParallelWorkers: runtime.NumCPU(),
+		}, nil
+	case "20ng":
+		return &documents.NewsgroupsSource{
+			Dir:             c.String("input"),
+			RemoveQuotes:    c.Bool("remove-quotes"),
+			ParallelWorkers: runtime.NumCPU(),
+		}, nil
+	case "rcv1":
+		source := &documents.RCV1Source{
+			Dir:             c.String("input"),
+			ParallelWorkers: runtime.NumCPU(),
+		}
+		if c.String("rcv1-hierarchy") != "" {
+			hierarchy, err := documents.ReadTopicHierarchyFile(c.String("rcv1-hierarchy"))
+			if err != nil {
+				return nil, err
+			}
+			source.Hierarchy = hierarchy
+		}
+		return source, nil
+	case "html":
+		return &documents.HTMLSource{
+			Dir:             c.String("input"),
+			ParallelWorkers: runtime.NumCPU(),
+		}, nil
+	case "email":
+		return &documents.EmailSource{
+			Dir:             c.String("input"),
+			ClassHeader:     c.String("class-header"),
+			ParallelWorkers: runtime.NumCPU(),
+		}, nil
+	case "wikipedia":
+		return &documents.WikipediaSource{
+			Path:            c.String("input"),
+			ParallelWorkers: runtime.NumCPU(),
+		}, nil
+	case "jsonl":
+		return &documents.JSONLSource{Path: c.String("input")}, nil
+	case "csv":
+		columns, err := documents.ParseCSVColumns(c.String("csv-columns"))
+		if err != nil {
+			return nil, err
+		}
+		return &documents.CSVSource{
+			Path:           c.String("input"),
+			Columns:        columns,
+			ClassSeparator: c.String("csv-class-separator"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %s (available: xml, sgml, txt, 20ng, rcv1, html, email, wikipedia, jsonl, csv)", c.String("format"))
 	}
 }
@@ -0,0 +1,82 @@
+package documents
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPost = `From: someone@example.com (Someone)
+Subject: Re: Shuttle launch
+Organization: NASA
+Lines: 6
+
+In article <1993Apr5.1234@example.com>, other@example.com writes:
+> When is the next launch?
+|> Quoted twice
+The launch is next week.
+Weather permitting.
+`
+
+func TestParseNewsgroupsPost(t *testing.T) {
+	assert := assert.New(t)
+
+	doc := ParseNewsgroupsPost(testPost, false)
+	assert.Equal("Re: Shuttle launch", doc.Title)
+	assert.True(strings.HasPrefix(doc.Body, "In article"))
+	assert.NotContains(doc.Body, "Organization")
+
+	doc = ParseNewsgroupsPost(testPost, true)
+	assert.Equal("The launch is next week.\nWeather permitting.\n", doc.Body)
+
+	doc = ParseNewsgroupsPost("no headers here\n\nat all", false)
+	assert.Equal("no headers here\n\nat all", doc.Body)
+}
+
+const testRCV1 = "<?xml version=\"1.0\" encoding=\"iso-8859-1\" ?>\n" +
+	`<newsitem itemid="2286" id="root" date="1996-08-20" xml:lang="en">
+<title>MEXICO: Recovery excitement brings Mexican markets to life.</title>
+<headline>Recovery excitement brings Mexican markets to life.</headline>
+<text>
+<p>Emerging evidence that Mexico's economy was back on the recovery track.</p>
+<p>Caf` + "\xe9" + ` prices rose.</p>
+</text>
+<metadata>
+<codes class="bip:countries:1.0">
+  <code code="MEX"><editdetail attribution="Reuters BIP Coding Group" action="confirmed" date="1996-08-20"/></code>
+</codes>
+<codes class="bip:topics:1.0">
+  <code code="E11"></code>
+  <code code="M11"></code>
+</codes>
+</metadata>
+</newsitem>`
+
+const testHierarchy = `parent: None         child: Root         child-description: Root
+parent: Root         child: ECAT         child-description: ECONOMICS
+parent: ECAT         child: E11          child-description: ECONOMIC PERFORMANCE
+parent: Root         child: MCAT         child-description: MARKETS
+parent: MCAT         child: M11          child-description: EQUITY MARKETS
+`
+
+func TestParseRCV1(t *testing.T) {
+	assert := assert.New(t)
+
+	doc, err := ParseRCV1(strings.NewReader(testRCV1), nil)
+	assert.Nil(err)
+	assert.Equal("2286", doc.ID)
+	assert.Equal("Recovery excitement brings Mexican markets to life.", doc.Title)
+	assert.Equal("1996-08-20", doc.Date)
+	assert.Contains(doc.Body, "Café prices rose.")
+	assert.Equal([]string{"E11", "M11"}, doc.Classes)
+	assert.Equal([]string{"MEX"}, doc.Labels["countries"])
+
+	hierarchy, err := ReadTopicHierarchy(strings.NewReader(testHierarchy))
+	assert.Nil(err)
+	assert.Equal(map[string]string{"E11": "ECAT", "M11": "MCAT"}, hierarchy)
+
+	doc, err = ParseRCV1(strings.NewReader(testRCV1), hierarchy)
+	assert.Nil(err)
+	assert.Equal([]string{"E11", "ECAT", "M11", "MCAT"}, doc.Classes)
+}
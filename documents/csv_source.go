@@ -0,0 +1,175 @@
+package documents
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CSVColumns maps document fields to the names of CSV columns. Empty
+// names mean that the field isn't present. The default columns of fields
+// other than the body may be missing from the file, but the columns given
+// to ParseCSVColumns must be there.
+type CSVColumns struct {
+	ID      string
+	Title   string
+	Body    string
+	Classes string
+	Date    string
+
+	mapped map[string]bool // fields whose columns were given explicitly
+}
+
+// DefaultCSVColumns expects columns with the same names as the fields
+func DefaultCSVColumns() CSVColumns {
+	return CSVColumns{
+		ID:      "id",
+		Title:   "title",
+		Body:    "body",
+		Classes: "classes",
+		Date:    "date",
+	}
+}
+
+// ParseCSVColumns overrides the default column names with a mapping of the
+// form "title=headline,body=text"
+func ParseCSVColumns(mapping string) (CSVColumns, error) {
+	columns := DefaultCSVColumns()
+	if mapping == "" {
+		return columns, nil
+	}
+	columns.mapped = make(map[string]bool)
+
+	for _, pair := range strings.Split(mapping, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return columns, fmt.Errorf("invalid column mapping %s (expected field=column)", pair)
+		}
+
+		field, column := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		switch field {
+		case "id":
+			columns.ID = column
+		case "title":
+			columns.Title = column
+		case "body":
+			columns.Body = column
+		case "classes":
+			columns.Classes = column
+		case "date":
+			columns.Date = column
+		default:
+			return columns, fmt.Errorf("unknown document field %s (available: id, title, body, classes, date)", field)
+		}
+		columns.mapped[field] = true
+	}
+
+	return columns, nil
+}
+
+// CSVSource reads a CSV file with a header row. The classes column may
+// hold several classes separated by ClassSeparator. Documents without an
+// ID are identified by their row number.
+type CSVSource struct {
+	Path           string
+	Columns        CSVColumns
+	ClassSeparator string // ";" if empty
+}
+
+func (s *CSVSource) Read(documents chan<- *Document) error {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return fmt.Errorf("unable to open file: %s", err)
+	}
+	defer f.Close()
+
+	return s.read(f, documents)
+}
+
+func (s *CSVSource) read(r io.Reader, documents chan<- *Document) error {
+	separator := s.ClassSeparator
+	if separator == "" {
+		separator = ";"
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("unable to read header: %s", err)
+	}
+
+	indices := make(map[string]int)
+	for i, name := range header {
+		indices[strings.TrimSpace(name)] = i
+	}
+
+	column := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		i, ok := indices[name]
+		if !ok {
+			return -1, fmt.Errorf("no column %s in %s", name, s.Path)
+		}
+		return i, nil
+	}
+
+	fields := [5]struct{ field, name string }{
+		{"id", s.Columns.ID},
+		{"title", s.Columns.Title},
+		{"body", s.Columns.Body},
+		{"classes", s.Columns.Classes},
+		{"date", s.Columns.Date},
+	}
+
+	var columns [5]int
+	for i, f := range fields {
+		columns[i], err = column(f.name)
+		// missing default columns are fine, but there must be a body, and
+		// the columns which were asked for
+		if err != nil && (f.field == "body" || s.Columns.mapped[f.field]) {
+			return err
+		}
+	}
+	id, title, body, classes, date := columns[0], columns[1], columns[2], columns[3], columns[4]
+
+	field := func(record []string, i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		document := &Document{
+			ID:    field(record, id),
+			Title: field(record, title),
+			Body:  field(record, body),
+			Date:  field(record, date),
+		}
+		if document.ID == "" {
+			document.ID = strconv.Itoa(row)
+		}
+
+		for _, class := range strings.Split(field(record, classes), separator) {
+			if class = strings.TrimSpace(class); class != "" {
+				document.Classes = append(document.Classes, class)
+			}
+		}
+
+		documents <- document
+	}
+}
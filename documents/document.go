@@ -6,10 +6,51 @@ import (
 )
 
 type Document struct {
+	// ID identifies the document within its source, e.g. by a file path.
+	// For Reuters documents, NewID is used instead.
+	ID string
+
 	Title   string
-	Classes []string
+	Classes []string // the topics
 	Body    string
 	Date    string
+
+	// Language is the name of the language of the document (as in
+	// analysis.Languages), detected when it is counted if the source
+	// doesn't set it
+	Language string
+
+	// Labels holds every set of categories by name, including the topics,
+	// e.g. Labels["places"]
+	Labels map[string][]string
+
+	// Reuters-21578 document identifiers
+	OldID string
+	NewID string
+
+	// Reuters-21578 attributes which define the standard splits
+	LewisSplit string // TRAIN, TEST or NOT-USED
+	CGISplit   string // TRAINING-SET or PUBLISHED-TESTSET
+	Topics     string // YES, NO or BYPASS
+}
+
+// LabelSetNames are the label sets of Reuters-21578 documents
+var LabelSetNames = []string{"topics", "places", "people", "orgs", "exchanges"}
+
+// Name identifies the document by its ID (if it has one) and title
+func (d *Document) Name() string {
+	id := d.ID
+	if id == "" {
+		id = d.NewID
+	}
+
+	if id == "" {
+		return d.Title
+	}
+	if d.Title == "" {
+		return id
+	}
+	return id + " " + d.Title
 }
 
 func (d *Document) String() string {
@@ -0,0 +1,277 @@
+package documents
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EmailSource reads .eml files (one message each) and mbox files (named
+// *.mbox or mbox) from a directory tree. The class of a message is the
+// value of ClassHeader if set (e.g. List-Id), and otherwise its folder:
+// the parent directory of an .eml file, or the name of an mbox file.
+type EmailSource struct {
+	Dir             string
+	ClassHeader     string
+	ParallelWorkers int
+}
+
+func (s *EmailSource) Read(documents chan<- *Document) error {
+	var files []string
+	for _, pattern := range []string{"*.eml", "*.mbox", "mbox"} {
+		matches, err := walkFiles(s.Dir, pattern)
+		if err != nil {
+			return fmt.Errorf("unable to list files in %s: %s", s.Dir, err)
+		}
+		files = append(files, matches...)
+	}
+
+	// the files are sorted by pattern first, so sort them again to keep
+	// the order of the directory tree
+	sort.Strings(files)
+
+	readFilesInOrder(files, s.ParallelWorkers, func(filename string) []*Document {
+		documentsInFile, err := s.readFile(filename)
+		if err != nil {
+			log.Printf("unable to read %s: %s", filename, err)
+		}
+		return documentsInFile
+	}, documents)
+
+	return nil
+}
+
+func (s *EmailSource) readFile(filename string) ([]*Document, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	id, err := filepath.Rel(s.Dir, filename)
+	if err != nil {
+		id = filename
+	}
+	id = filepath.ToSlash(id)
+
+	if strings.HasSuffix(filename, ".eml") {
+		folder := ""
+		if dir := path.Dir(id); dir != "." {
+			folder = path.Base(dir)
+		}
+
+		document, err := s.parse(f, id, folder)
+		if err != nil {
+			return nil, err
+		}
+		return []*Document{document}, nil
+	}
+
+	var documents []*Document
+	folder := strings.TrimSuffix(path.Base(id), ".mbox")
+	err = SplitMbox(f, func(i int, message []byte) {
+		document, err := s.parse(bytes.NewReader(message), id+"#"+strconv.Itoa(i), folder)
+		if err != nil {
+			log.Printf("unable to parse message %d of %s: %s", i, filename, err)
+			return
+		}
+		documents = append(documents, document)
+	})
+	return documents, err
+}
+
+func (s *EmailSource) parse(r io.Reader, id string, folder string) (*Document, error) {
+	message, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := ParseEmail(message)
+	if err != nil {
+		return nil, err
+	}
+	document.ID = id
+
+	class := folder
+	if s.ClassHeader != "" {
+		class = decodeHeader(message.Header.Get(s.ClassHeader))
+	}
+	if class != "" {
+		document.Classes = []string{class}
+	}
+
+	return document, nil
+}
+
+// SplitMbox calls message with every message of an mbox file, in order.
+// Messages start with a "From " line, and ">From " quoting is undone.
+func SplitMbox(r io.Reader, message func(i int, message []byte)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	var current []byte
+	i := 0
+	started := false
+	previousEmpty := true
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		if previousEmpty && bytes.HasPrefix(line, []byte("From ")) {
+			if started {
+				message(i, current)
+				i += 1
+			}
+			current = nil
+			started = true
+			previousEmpty = false
+			continue
+		}
+		previousEmpty = len(line) == 0
+
+		if !started {
+			continue
+		}
+
+		if bytes.HasPrefix(line, []byte(">From ")) {
+			line = line[1:]
+		}
+		current = append(current, line...)
+		current = append(current, '\n')
+	}
+
+	if started {
+		message(i, current)
+	}
+
+	return scanner.Err()
+}
+
+// ParseEmail maps the subject, date and text of a message to a document.
+// Plain text parts are preferred, and HTML parts are only used if there
+// are none.
+func ParseEmail(message *mail.Message) (*Document, error) {
+	document := &Document{
+		Title: decodeHeader(message.Header.Get("Subject")),
+		Date:  message.Header.Get("Date"),
+	}
+
+	plain, html, err := emailText(
+		message.Header.Get("Content-Type"),
+		message.Header.Get("Content-Transfer-Encoding"),
+		message.Body,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if plain != "" {
+		document.Body = plain
+	} else {
+		_, document.Body = ExtractHTMLText(strings.NewReader(html))
+	}
+
+	return document, nil
+}
+
+// emailText returns the first plain text and the first HTML part of a
+// (possibly multipart) message body
+func emailText(contentType string, encoding string, body io.Reader) (plain string, html string, err error) {
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// broken content types are common, so treat the body as text
+		mediaType, params = "text/plain", nil
+	}
+
+	body = decodeTransfer(encoding, body)
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return plain, html, err
+			}
+
+			// multipart.Reader already decodes quoted-printable parts
+			partPlain, partHTML, err := emailText(
+				part.Header.Get("Content-Type"),
+				part.Header.Get("Content-Transfer-Encoding"),
+				part,
+			)
+			if err != nil {
+				return plain, html, err
+			}
+			if plain == "" {
+				plain = partPlain
+			}
+			if html == "" {
+				html = partHTML
+			}
+		}
+		return plain, html, nil
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		// attachments
+		return "", "", nil
+	}
+
+	if charset := params["charset"]; charset != "" {
+		decoded, err := charsetReader(charset, body)
+		if err == nil {
+			body = decoded
+		}
+	}
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return "", "", err
+	}
+
+	if mediaType == "text/html" {
+		return "", string(data), nil
+	}
+	return string(data), "", nil
+}
+
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	default:
+		return body
+	}
+}
+
+// decodeHeader decodes RFC 2047 encoded words such as =?UTF-8?Q?...?=
+func decodeHeader(value string) string {
+	decoder := &mime.WordDecoder{CharsetReader: charsetReader}
+	decoded, err := decoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
@@ -0,0 +1,70 @@
+package documents
+
+import (
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testMbox = `From alice@example.com Mon Jan  1 00:00:00 2018
+From: Alice <alice@example.com>
+Subject: =?UTF-8?Q?Caf=C3=A9_news?=
+Date: Mon, 1 Jan 2018 00:00:00 +0000
+List-Id: <newsletter.example.com>
+Content-Type: multipart/alternative; boundary="XYZ"
+
+--XYZ
+Content-Type: text/html; charset=utf-8
+
+<p>HTML version</p>
+--XYZ
+Content-Type: text/plain; charset=utf-8
+Content-Transfer-Encoding: quoted-printable
+
+Plain version with a long line that is=
+ wrapped.
+>From the archive.
+--XYZ--
+
+From bob@example.com Tue Jan  2 00:00:00 2018
+From: Bob <bob@example.com>
+Subject: Only HTML
+Content-Type: text/html
+Content-Transfer-Encoding: base64
+
+PGh0bWw+PGJvZHk+PHA+SGVsbG8gd29ybGQ8L3A+PC9ib2R5PjwvaHRtbD4=
+`
+
+func TestEmail(t *testing.T) {
+	assert := assert.New(t)
+
+	var messages [][]byte
+	err := SplitMbox(strings.NewReader(testMbox), func(i int, message []byte) {
+		assert.Equal(len(messages), i)
+		messages = append(messages, message)
+	})
+	assert.Nil(err)
+	assert.Equal(2, len(messages))
+
+	source := &EmailSource{}
+	first, err := source.parse(strings.NewReader(string(messages[0])), "news.mbox#0", "news")
+	assert.Nil(err)
+	assert.Equal("Café news", first.Title)
+	assert.Equal("Mon, 1 Jan 2018 00:00:00 +0000", first.Date)
+	assert.Equal("Plain version with a long line that is wrapped.\nFrom the archive.", strings.TrimSpace(first.Body))
+	assert.Equal([]string{"news"}, first.Classes)
+
+	source.ClassHeader = "List-Id"
+	first, err = source.parse(strings.NewReader(string(messages[0])), "news.mbox#0", "news")
+	assert.Nil(err)
+	assert.Equal([]string{"<newsletter.example.com>"}, first.Classes)
+
+	message, err := mail.ReadMessage(strings.NewReader(string(messages[1])))
+	assert.Nil(err)
+	second, err := ParseEmail(message)
+	assert.Nil(err)
+	assert.Equal("Only HTML", second.Title)
+	assert.Equal("Hello world", second.Body)
+}
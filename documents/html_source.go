@@ -0,0 +1,177 @@
+package documents
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// HTMLSource reads a directory tree of HTML files, keeping only their
+// visible text. Like TextSource, the class of each file is the name of its
+// parent directory.
+type HTMLSource struct {
+	Dir             string
+	ParallelWorkers int
+}
+
+func (s *HTMLSource) Read(documents chan<- *Document) error {
+	var files []string
+	for _, pattern := range []string{"*.html", "*.htm"} {
+		matches, err := walkFiles(s.Dir, pattern)
+		if err != nil {
+			return fmt.Errorf("unable to list files in %s: %s", s.Dir, err)
+		}
+		files = append(files, matches...)
+	}
+
+	// the files are sorted by pattern first, so sort them again to keep
+	// the order of the directory tree
+	sort.Strings(files)
+
+	readFilesInOrder(files, s.ParallelWorkers, func(filename string) []*Document {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			log.Printf("unable to read %s: %s", filename, err)
+			return nil
+		}
+
+		id, err := filepath.Rel(s.Dir, filename)
+		if err != nil {
+			id = filename
+		}
+		id = filepath.ToSlash(id)
+
+		title, text := ExtractHTMLText(bytes.NewReader(data))
+		document := &Document{
+			ID:    id,
+			Title: title,
+			Body:  text,
+		}
+		if dir := path.Dir(id); dir != "." {
+			document.Classes = []string{path.Base(dir)}
+		}
+
+		return []*Document{document}
+	}, documents)
+
+	return nil
+}
+
+// elements whose text isn't part of the content
+var hiddenElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"noscript": true,
+	"template": true,
+	"nav":      true,
+	"header":   true,
+	"footer":   true,
+	"aside":    true,
+	"form":     true,
+	"button":   true,
+	"select":   true,
+	"iframe":   true,
+	"svg":      true,
+}
+
+// elements which separate lines of text (table cells are put on separate
+// lines too, so that adjacent cells aren't joined into one word)
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"section": true, "article": true, "blockquote": true, "pre": true,
+	"table": true, "ul": true, "ol": true, "dd": true, "dt": true,
+	"td": true, "th": true, "caption": true,
+}
+
+var (
+	// scripts and styles may contain unescaped '<', which would confuse
+	// the parser, so they're removed beforehand
+	scriptRegex = regexp.MustCompile(`(?is)<script\b.*?</script\s*>`)
+	styleRegex  = regexp.MustCompile(`(?is)<style\b.*?</style\s*>`)
+	spaceRegex  = regexp.MustCompile(`[ \t\r\f\v\x{a0}]+`)
+)
+
+// ExtractHTMLText returns the title and the visible text of an HTML page,
+// without scripts, styles and navigation boilerplate. HTML doesn't need to
+// be well-formed: the parser invents missing end tags, and if it gives up,
+// the text up to that point is returned.
+func ExtractHTMLText(r io.Reader) (title string, text string) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", ""
+	}
+	data = scriptRegex.ReplaceAll(data, nil)
+	data = styleRegex.ReplaceAll(data, nil)
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = charsetReader
+
+	var body strings.Builder
+	var titleText strings.Builder
+	var stack []string
+	hidden := 0 // number of hidden elements on the stack
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			stack = append(stack, name)
+			if hiddenElements[name] {
+				hidden += 1
+			}
+			if blockElements[name] {
+				body.WriteString("\n")
+			}
+		case xml.EndElement:
+			if len(stack) == 0 {
+				continue
+			}
+			name := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if hiddenElements[name] {
+				hidden -= 1
+			}
+			if blockElements[name] {
+				body.WriteString("\n")
+			}
+		case xml.CharData:
+			switch {
+			case len(stack) > 0 && stack[len(stack)-1] == "title":
+				titleText.Write(t)
+			case hidden == 0:
+				body.Write(t)
+			}
+		}
+	}
+
+	return collapseSpace(titleText.String()), collapseSpace(body.String())
+}
+
+// collapseSpace joins runs of spaces and drops empty lines
+func collapseSpace(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(spaceRegex.ReplaceAllString(line, " "))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
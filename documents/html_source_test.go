@@ -0,0 +1,59 @@
+package documents
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testHTML = `<!DOCTYPE html>
+<html><head><title>Weekly  news</title>
+<style>p { color: red; }</style>
+<script>if (a < b) { document.write("<p>hidden</p>"); }</script>
+</head>
+<body>
+<nav><a href="/">Home</a> | <a href="/about">About</a></nav>
+<h1>Headline</h1>
+<p>First&nbsp;paragraph with <b>bold</b> text &amp; more.<br>
+Second line
+<div>Unclosed div
+<footer>Copyright</footer>
+</body></html>`
+
+func TestExtractHTMLText(t *testing.T) {
+	assert := assert.New(t)
+
+	title, text := ExtractHTMLText(strings.NewReader(testHTML))
+	assert.Equal("Weekly news", title)
+	assert.Equal("Headline\nFirst paragraph with bold text & more.\nSecond line\nUnclosed div", text)
+
+	_, text = ExtractHTMLText(strings.NewReader("<table><caption>Prices</caption><tr><th>crude</th><th>wheat</th></tr><tr><td>cell1</td><td>cell2</td></tr></table>"))
+	assert.Equal("Prices\ncrude\nwheat\ncell1\ncell2", text)
+}
+
+func TestHTMLSourceOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "html")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	assert.Nil(os.Mkdir(filepath.Join(dir, "news"), 0755))
+	for _, name := range []string{"c.html", "b.htm", "a.html"} {
+		assert.Nil(ioutil.WriteFile(filepath.Join(dir, "news", name), []byte("<p>"+name+"</p>"), 0644))
+	}
+
+	docs := make(chan *Document, 10)
+	assert.Nil((&HTMLSource{Dir: dir, ParallelWorkers: 2}).Read(docs))
+	close(docs)
+
+	var ids []string
+	for doc := range docs {
+		ids = append(ids, doc.ID)
+	}
+	assert.Equal([]string{"news/a.html", "news/b.htm", "news/c.html"}, ids)
+}
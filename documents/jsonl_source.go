@@ -0,0 +1,73 @@
+package documents
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+)
+
+// JSONLSource reads a file with one JSON document per line, with the
+// fields "title", "body", "classes", "date" and optionally "id". Documents
+// without an ID are identified by their line number.
+type JSONLSource struct {
+	Path string
+}
+
+type jsonDocument struct {
+	ID      string   `json:"id"`
+	Title   string   `json:"title"`
+	Body    string   `json:"body"`
+	Classes []string `json:"classes"`
+	Date    string   `json:"date"`
+	// Language is detected when counting if empty
+	Language string `json:"language"`
+}
+
+func (s *JSONLSource) Read(documents chan<- *Document) error {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return fmt.Errorf("unable to open file: %s", err)
+	}
+	defer f.Close()
+
+	return readJSONL(f, s.Path, documents)
+}
+
+func readJSONL(r io.Reader, filename string, documents chan<- *Document) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line += 1
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var doc jsonDocument
+		err := json.Unmarshal(scanner.Bytes(), &doc)
+		if err != nil {
+			log.Printf("%s:%d: unable to parse document: %s", filename, line, err)
+			continue
+		}
+
+		if doc.ID == "" {
+			doc.ID = strconv.Itoa(line)
+		}
+
+		documents <- &Document{
+			ID:       doc.ID,
+			Title:    doc.Title,
+			Body:     doc.Body,
+			Classes:  doc.Classes,
+			Date:     doc.Date,
+			Language: doc.Language,
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,108 @@
+package documents
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// NewsgroupsSource reads the 20 Newsgroups corpus, laid out as one
+// directory per newsgroup (e.g. 20news-bydate-train/sci.space/60804). The
+// headers are stripped from the posts, except for the subject (which
+// becomes the title) and the date.
+type NewsgroupsSource struct {
+	Dir string
+	// RemoveQuotes drops quoted lines of replies and the lines which
+	// introduce them ("In article <...>, someone writes:"), so that a
+	// classifier can't rely on the text of other posts
+	RemoveQuotes    bool
+	ParallelWorkers int
+}
+
+func (s *NewsgroupsSource) Read(documents chan<- *Document) error {
+	files, err := walkFiles(s.Dir, "*")
+	if err != nil {
+		return fmt.Errorf("unable to list files in %s: %s", s.Dir, err)
+	}
+
+	readFilesInOrder(files, s.ParallelWorkers, func(filename string) []*Document {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			log.Printf("unable to read %s: %s", filename, err)
+			return nil
+		}
+
+		id, err := filepath.Rel(s.Dir, filename)
+		if err != nil {
+			id = filename
+		}
+		id = filepath.ToSlash(id)
+
+		document := ParseNewsgroupsPost(string(data), s.RemoveQuotes)
+		document.ID = id
+		if dir := path.Dir(id); dir != "." {
+			document.Classes = []string{path.Base(dir)}
+		}
+
+		return []*Document{document}
+	}, documents)
+
+	return nil
+}
+
+var (
+	headerRegex      = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*:`)
+	attributionRegex = regexp.MustCompile(`(?i)(^in article\s*<|(writes|wrote|says):\s*$)`)
+)
+
+// ParseNewsgroupsPost splits a post into headers and body. Only the
+// subject and date headers are kept.
+func ParseNewsgroupsPost(post string, removeQuotes bool) *Document {
+	document := &Document{}
+
+	lines := strings.Split(strings.Replace(post, "\r\n", "\n", -1), "\n")
+
+	// headers continue until the first empty line, but only if the post
+	// starts with one
+	bodyStart := 0
+	if len(lines) > 0 && headerRegex.MatchString(lines[0]) {
+		bodyStart = len(lines)
+		for i, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				bodyStart = i + 1
+				break
+			}
+
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			value := strings.TrimSpace(parts[1])
+
+			switch strings.ToLower(parts[0]) {
+			case "subject":
+				document.Title = value
+			case "date":
+				document.Date = value
+			}
+		}
+	}
+
+	var body []string
+	for _, line := range lines[bodyStart:] {
+		if removeQuotes {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, ">") || strings.HasPrefix(trimmed, "|") || attributionRegex.MatchString(trimmed) {
+				continue
+			}
+		}
+		body = append(body, line)
+	}
+	document.Body = strings.Join(body, "\n")
+
+	return document
+}
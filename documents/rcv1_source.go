@@ -0,0 +1,216 @@
+package documents
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// RCV1Source reads the RCV1-v2 corpus, which has one XML file per news
+// item. The bip:topics codes become the classes, and the topic, country
+// and industry codes are kept as the label sets "topics", "countries" and
+// "industries".
+type RCV1Source struct {
+	Dir string
+	// Hierarchy maps topic codes to their parents (see
+	// ReadTopicHierarchy). If set, the ancestors of every topic are added
+	// to the topics of the document.
+	Hierarchy       map[string]string
+	ParallelWorkers int
+}
+
+// the label sets of RCV1 documents by the class of their codes
+var rcv1LabelSets = map[string]string{
+	"bip:topics:1.0":     "topics",
+	"bip:countries:1.0":  "countries",
+	"bip:industries:1.0": "industries",
+}
+
+type rcv1NewsItem struct {
+	ItemID     string   `xml:"itemid,attr"`
+	Date       string   `xml:"date,attr"`
+	Title      string   `xml:"title"`
+	Headline   string   `xml:"headline"`
+	Paragraphs []string `xml:"text>p"`
+	Codes      []struct {
+		Class string `xml:"class,attr"`
+		Codes []struct {
+			Code string `xml:"code,attr"`
+		} `xml:"code"`
+	} `xml:"metadata>codes"`
+}
+
+func (s *RCV1Source) Read(documents chan<- *Document) error {
+	files, err := walkFiles(s.Dir, "*.xml")
+	if err != nil {
+		return fmt.Errorf("unable to list files in %s: %s", s.Dir, err)
+	}
+
+	readFilesInOrder(files, s.ParallelWorkers, func(filename string) []*Document {
+		document, err := s.readFile(filename)
+		if err != nil {
+			log.Printf("unable to parse %s: %s", filename, err)
+			return nil
+		}
+		return []*Document{document}
+	}, documents)
+
+	return nil
+}
+
+func (s *RCV1Source) readFile(filename string) (*Document, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseRCV1(f, s.Hierarchy)
+}
+
+// ParseRCV1 parses a single RCV1 news item. The hierarchy may be nil.
+func ParseRCV1(r io.Reader, hierarchy map[string]string) (*Document, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charsetReader
+
+	var item rcv1NewsItem
+	err := decoder.Decode(&item)
+	if err != nil {
+		return nil, err
+	}
+
+	document := &Document{
+		ID:     item.ItemID,
+		Title:  strings.TrimSpace(item.Headline),
+		Body:   strings.Join(item.Paragraphs, "\n"),
+		Date:   item.Date,
+		Labels: make(map[string][]string),
+	}
+	if document.Title == "" {
+		document.Title = strings.TrimSpace(item.Title)
+	}
+
+	for _, codes := range item.Codes {
+		labelSet, ok := rcv1LabelSets[codes.Class]
+		if !ok {
+			continue
+		}
+
+		for _, code := range codes.Codes {
+			document.Labels[labelSet] = append(document.Labels[labelSet], code.Code)
+		}
+	}
+
+	if hierarchy != nil {
+		document.Labels["topics"] = withAncestors(document.Labels["topics"], hierarchy)
+	}
+	document.Classes = document.Labels["topics"]
+
+	return document, nil
+}
+
+// ReadTopicHierarchy reads the RCV1 topic hierarchy file
+// (rcv1.topics.hier.orig), which has lines of the form
+// "parent: C15  child: C151  child-description: ...", and returns a map
+// of each topic to its parent. The root of the hierarchy is omitted.
+func ReadTopicHierarchy(r io.Reader) (map[string]string, error) {
+	hierarchy := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[0] != "parent:" || fields[2] != "child:" {
+			continue
+		}
+
+		if fields[1] == "Root" || fields[1] == "None" {
+			continue
+		}
+		hierarchy[fields[3]] = fields[1]
+	}
+
+	return hierarchy, scanner.Err()
+}
+
+// ReadTopicHierarchyFile reads the topic hierarchy from a file
+func ReadTopicHierarchyFile(filename string) (map[string]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file: %s", err)
+	}
+	defer f.Close()
+
+	return ReadTopicHierarchy(f)
+}
+
+// withAncestors adds the ancestors of all codes to them, without
+// duplicates and sorted
+func withAncestors(codes []string, hierarchy map[string]string) []string {
+	set := make(map[string]struct{})
+	for _, code := range codes {
+		for ; code != ""; code = hierarchy[code] {
+			if _, ok := set[code]; ok {
+				break
+			}
+			set[code] = struct{}{}
+		}
+	}
+
+	all := make([]string, 0, len(set))
+	for code := range set {
+		all = append(all, code)
+	}
+	sort.Strings(all)
+
+	return all
+}
+
+// charsetReader decodes ISO-8859-1, which RCV1 uses, into UTF-8
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "latin-1":
+		return &latin1Reader{r: bufio.NewReader(input)}, nil
+	case "utf-8", "us-ascii":
+		return input, nil
+	default:
+		return nil, fmt.Errorf("unsupported charset %s", charset)
+	}
+}
+
+type latin1Reader struct {
+	r       *bufio.Reader
+	pending []byte
+}
+
+func (l *latin1Reader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(l.pending) > 0 {
+			copied := copy(p[n:], l.pending)
+			l.pending = l.pending[copied:]
+			n += copied
+			continue
+		}
+
+		b, err := l.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+
+		if b < 0x80 {
+			p[n] = b
+			n += 1
+		} else {
+			l.pending = []byte(string(rune(b)))
+		}
+	}
+	return n, nil
+}
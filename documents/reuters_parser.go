@@ -1,3 +1,6 @@
+//go:build cgo
+// +build cgo
+
 package documents
 
 import (
@@ -12,6 +15,8 @@ import (
 	"github.com/jbowtie/gokogiri/xml"
 )
 
+// ReutersParser parses Reuters-21578 documents converted to well-formed
+// XML, using libxml2. Without cgo, SGMLParser is used instead.
 type ReutersParser struct{}
 
 func NewReutersParser() *ReutersParser {
@@ -35,6 +40,11 @@ func (r *ReutersParser) ParseFiles(filenames <-chan string, documents chan<- *Do
 	}
 }
 
+// parseReutersFile parses a whole converted file with the XML parser
+func parseReutersFile(filename string) ([]*Document, error) {
+	return NewReutersParser().ParseFile(filename)
+}
+
 func (r *ReutersParser) ParseFile(filename string) ([]*Document, error) {
 	f, err := os.Open(filename)
 	if err != nil {
@@ -81,6 +91,12 @@ func (r *ReutersParser) Parse(data []byte) ([]*Document, error) {
 }
 
 func (r *ReutersParser) parseDocument(node xml.Node, document *Document) error {
+	document.LewisSplit = node.Attr("LEWISSPLIT")
+	document.CGISplit = node.Attr("CGISPLIT")
+	document.Topics = node.Attr("TOPICS")
+	document.OldID = node.Attr("OLDID")
+	document.NewID = node.Attr("NEWID")
+
 	titleNode, err := htmlparsing.First(node, ".//TITLE")
 	if err == nil {
 		// don't care if there's no title
@@ -99,15 +115,21 @@ func (r *ReutersParser) parseDocument(node xml.Node, document *Document) error {
 		document.Date = dateNode.Content()
 	}
 
-	topicNodes, err := node.Search(".//TOPICS/D")
-	if err != nil {
-		return fmt.Errorf("Unable to parse document topics: %s", err)
-	}
+	document.Labels = make(map[string][]string)
+	for _, labelSet := range LabelSetNames {
+		labelNodes, err := node.Search(".//" + strings.ToUpper(labelSet) + "/D")
+		if err != nil {
+			return fmt.Errorf("Unable to parse document %s: %s", labelSet, err)
+		}
 
-	document.Classes = make([]string, len(topicNodes))
-	for i := range topicNodes {
-		document.Classes[i] = topicNodes[i].Content()
+		labels := make([]string, len(labelNodes))
+		for i := range labelNodes {
+			labels[i] = labelNodes[i].Content()
+		}
+		document.Labels[labelSet] = labels
 	}
 
+	document.Classes = document.Labels["topics"]
+
 	return nil
 }
@@ -0,0 +1,19 @@
+//go:build !cgo
+// +build !cgo
+
+package documents
+
+// ReutersParser is SGMLParser when built without cgo, since the XML parser
+// needs libxml2
+type ReutersParser = SGMLParser
+
+func NewReutersParser() *ReutersParser {
+	return NewSGMLParser()
+}
+
+// parseReutersFile parses a whole converted file with the SGML parser
+func parseReutersFile(filename string) ([]*Document, error) {
+	return collectDocuments(func(documents chan<- *Document) error {
+		return NewSGMLParser().ParseFile(filename, documents)
+	})
+}
@@ -0,0 +1,259 @@
+package documents
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SGMLParser parses the original Reuters-21578 .sgm files. It doesn't need
+// the files to be well-formed XML, so it tolerates unescaped markup in the
+// text and character references such as &#3;. Documents are sent on as
+// soon as they are parsed, so files are never read into memory whole.
+type SGMLParser struct {
+	// OnError is called for every document which can't be parsed.
+	// By default the errors are logged.
+	OnError func(err *ParseError)
+}
+
+// ParseError is an error in a single document
+type ParseError struct {
+	File   string
+	Offset int64 // byte offset of the document in the file
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Offset, e.Err)
+}
+
+// errNoBody is reported for documents without a body, e.g. TYPE="BRIEF".
+// Like ReutersParser, the parser skips such documents without logging them.
+var errNoBody = fmt.Errorf("document has no body")
+
+func NewSGMLParser() *SGMLParser {
+	return &SGMLParser{
+		OnError: func(err *ParseError) {
+			if err.Err != errNoBody {
+				log.Printf("Unable to parse document: %s", err)
+			}
+		},
+	}
+}
+
+func (p *SGMLParser) ParseFiles(filenames <-chan string, documents chan<- *Document) {
+	for f := range filenames {
+		log.Printf("start parsing %s", f)
+		err := p.ParseFile(f, documents)
+		if err != nil {
+			log.Printf("Unable to parse file %s: %s", f, err)
+		} else {
+			log.Printf("finish parsing %s", f)
+		}
+	}
+}
+
+func (p *SGMLParser) ParseFile(filename string, documents chan<- *Document) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("Unable to open file: %s", err)
+	}
+	defer f.Close()
+
+	return p.Parse(f, filename, documents)
+}
+
+// Parse reads <REUTERS> documents from r and sends them to documents.
+// The filename is only used for error reporting.
+func (p *SGMLParser) Parse(r io.Reader, filename string, documents chan<- *Document) error {
+	reader := bufio.NewReaderSize(r, 64*1024)
+
+	var offset int64    // of the next chunk
+	var start int64     // of the current document
+	var document []byte // nil when outside of a document
+
+	for {
+		// chunks end with '>', so a tag is never split between two chunks
+		chunk, err := reader.ReadBytes('>')
+
+		if document == nil {
+			if i := indexOfTag(chunk, "<REUTERS"); i != -1 {
+				start = offset + int64(i)
+				document = append([]byte(nil), chunk[i:]...)
+			}
+		} else {
+			document = append(document, chunk...)
+		}
+
+		if document != nil && bytes.HasSuffix(document, []byte("</REUTERS>")) {
+			doc, docErr := parseSGMLDocument(document)
+			if docErr != nil {
+				p.OnError(&ParseError{File: filename, Offset: start, Err: docErr})
+			} else {
+				documents <- doc
+			}
+			document = nil
+		}
+
+		offset += int64(len(chunk))
+
+		if err == io.EOF {
+			if document != nil {
+				p.OnError(&ParseError{File: filename, Offset: start, Err: fmt.Errorf("unterminated document")})
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// indexOfTag finds an opening tag with the given prefix (e.g. "<REUTERS"),
+// making sure that it isn't just the start of a longer tag name
+func indexOfTag(data []byte, prefix string) int {
+	from := 0
+	for {
+		i := bytes.Index(data[from:], []byte(prefix))
+		if i == -1 {
+			return -1
+		}
+		i += from
+
+		end := i + len(prefix)
+		if end == len(data) || data[end] == '>' || data[end] == ' ' || data[end] == '\t' || data[end] == '\n' || data[end] == '\r' {
+			return i
+		}
+		from = end
+	}
+}
+
+var attributeRegex = regexp.MustCompile(`([A-Z]+)="([^"]*)"`)
+
+func parseSGMLDocument(data []byte) (*Document, error) {
+	s := string(data)
+
+	openingTag := s[:strings.IndexByte(s, '>')]
+	attributes := make(map[string]string)
+	for _, match := range attributeRegex.FindAllStringSubmatch(openingTag, -1) {
+		attributes[match[1]] = match[2]
+	}
+
+	document := &Document{
+		LewisSplit: attributes["LEWISSPLIT"],
+		CGISplit:   attributes["CGISPLIT"],
+		Topics:     attributes["TOPICS"],
+		OldID:      attributes["OLDID"],
+		NewID:      attributes["NEWID"],
+		Labels:     make(map[string][]string),
+	}
+
+	if date, ok := element(s, "DATE"); ok {
+		document.Date = strings.TrimSpace(unescape(date))
+	}
+
+	for _, labelSet := range LabelSetNames {
+		labels, _ := element(s, strings.ToUpper(labelSet))
+
+		var names []string
+		for {
+			label, ok := element(labels, "D")
+			if !ok {
+				break
+			}
+			names = append(names, unescape(label))
+			labels = labels[strings.Index(labels, "</D>")+len("</D>"):]
+		}
+		document.Labels[labelSet] = names
+	}
+	document.Classes = document.Labels["topics"]
+
+	text, ok := element(s, "TEXT")
+	if !ok {
+		return nil, fmt.Errorf("document has no text")
+	}
+
+	if title, ok := element(text, "TITLE"); ok {
+		document.Title = unescape(title)
+	}
+
+	body, ok := element(text, "BODY")
+	if !ok {
+		return nil, errNoBody
+	}
+	document.Body = unescape(body)
+
+	return document, nil
+}
+
+// element returns the content of the first element with the given name.
+// The opening tag may have attributes.
+func element(s string, name string) (string, bool) {
+	start := indexOfTag([]byte(s), "<"+name)
+	if start == -1 {
+		return "", false
+	}
+
+	contentStart := strings.IndexByte(s[start:], '>')
+	if contentStart == -1 {
+		return "", false
+	}
+	contentStart += start + 1
+
+	end := strings.Index(s[contentStart:], "</"+name+">")
+	if end == -1 {
+		return "", false
+	}
+
+	return s[contentStart : contentStart+end], true
+}
+
+var entityRegex = regexp.MustCompile(`&(#[0-9]+|#x[0-9a-fA-F]+|[a-z]+);`)
+
+var namedEntities = map[string]string{
+	"lt":   "<",
+	"gt":   ">",
+	"amp":  "&",
+	"quot": "\"",
+	"apos": "'",
+}
+
+// unescape replaces character references and drops control characters
+// (the bodies end with &#3;). Unknown entities are kept as they are.
+func unescape(s string) string {
+	s = entityRegex.ReplaceAllStringFunc(s, func(entity string) string {
+		name := entity[1 : len(entity)-1]
+
+		if name[0] != '#' {
+			if replacement, ok := namedEntities[name]; ok {
+				return replacement
+			}
+			return entity
+		}
+
+		var code int64
+		var err error
+		if name[1] == 'x' {
+			code, err = strconv.ParseInt(name[2:], 16, 32)
+		} else {
+			code, err = strconv.ParseInt(name[1:], 10, 32)
+		}
+		if err != nil {
+			return entity
+		}
+		return string(rune(code))
+	})
+
+	return strings.Map(func(r rune) rune {
+		if r < ' ' && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, s)
+}
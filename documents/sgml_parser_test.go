@@ -0,0 +1,78 @@
+package documents
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSGML = `<!DOCTYPE lewis SYSTEM "lewis.dtd">
+<REUTERS TOPICS="YES" LEWISSPLIT="TRAIN" CGISPLIT="TRAINING-SET" OLDID="5544" NEWID="1">
+<DATE>26-FEB-1987 15:01:01.79</DATE>
+<TOPICS><D>cocoa</D></TOPICS>
+<PLACES><D>el-salvador</D><D>usa</D></PLACES>
+<PEOPLE></PEOPLE>
+<ORGS></ORGS>
+<EXCHANGES></EXCHANGES>
+<COMPANIES></COMPANIES>
+<UNKNOWN>
+&#5;&#5;&#5;C T
+&#22;&#22;&#1;f0704&#31;reute
+</UNKNOWN>
+<TEXT>&#2;
+<TITLE>BAHIA COCOA &lt;REVIEW></TITLE>
+<DATELINE>    SALVADOR, Feb 26 - </DATELINE><BODY>Showers continued <throughout> the week
+in the Bahia cocoa zone &amp; more.
+ Reuter
+&#3;</BODY></TEXT>
+</REUTERS>
+<REUTERS TOPICS="NO" LEWISSPLIT="TEST" CGISPLIT="TRAINING-SET" OLDID="5545" NEWID="2">
+<DATE>26-FEB-1987 15:02:20.00</DATE>
+<TOPICS></TOPICS>
+<TEXT TYPE="BRIEF">&#2;
+<TITLE>STANDARD OIL &lt;SRD> TO FORM FINANCIAL UNIT
+</TITLE>Blah blah blah.
+&#3;
+</TEXT>
+</REUTERS>
+<REUTERS TOPICS="YES" LEWISSPLIT="TEST" CGISPLIT="TRAINING-SET" OLDID="5546" NEWID="3">
+<TEXT><BODY>Cut off`
+
+func TestSGMLParser(t *testing.T) {
+	assert := assert.New(t)
+
+	parser := NewSGMLParser()
+	var errors []*ParseError
+	parser.OnError = func(err *ParseError) {
+		errors = append(errors, err)
+	}
+
+	documents := make(chan *Document, 10)
+	assert.Nil(parser.Parse(strings.NewReader(testSGML), "test.sgm", documents))
+	close(documents)
+
+	var docs []*Document
+	for doc := range documents {
+		docs = append(docs, doc)
+	}
+
+	assert.Equal(1, len(docs))
+	doc := docs[0]
+	assert.Equal("BAHIA COCOA <REVIEW>", doc.Title)
+	assert.Equal("Showers continued <throughout> the week\nin the Bahia cocoa zone & more.\n Reuter\n", doc.Body)
+	assert.Equal("26-FEB-1987 15:01:01.79", doc.Date)
+	assert.Equal([]string{"cocoa"}, doc.Classes)
+	assert.Equal([]string{"el-salvador", "usa"}, doc.Labels["places"])
+	assert.Nil(doc.Labels["people"])
+	assert.Equal("5544", doc.OldID)
+	assert.Equal("1", doc.NewID)
+	assert.Equal("TRAIN", doc.LewisSplit)
+	assert.Equal("YES", doc.Topics)
+
+	assert.Equal(2, len(errors))
+	assert.Equal(errNoBody, errors[0].Err)
+	assert.Equal(int64(strings.Index(testSGML, `<REUTERS TOPICS="NO"`)), errors[0].Offset)
+	assert.Equal(int64(strings.LastIndex(testSGML, "<REUTERS")), errors[1].Offset)
+	assert.Contains(errors[1].Error(), "test.sgm:")
+}
@@ -0,0 +1,143 @@
+package documents
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/DexterLB/search/utils"
+)
+
+// Source produces the documents of a corpus
+type Source interface {
+	// Read sends all documents of the source to the channel, and returns
+	// when it's done. It doesn't close the channel.
+	Read(documents chan<- *Document) error
+}
+
+// ReutersSource reads the Reuters-21578 files in a directory, either the
+// .xml files converted to XML (with ReutersParser) or the original .sgm
+// files (with SGMLParser)
+type ReutersSource struct {
+	Dir             string
+	SGML            bool
+	ParallelWorkers int
+}
+
+func (s *ReutersSource) Read(documents chan<- *Document) error {
+	pattern := "*.xml"
+	if s.SGML {
+		pattern = "*.sgm"
+	}
+
+	files, err := filepath.Glob(filepath.Join(s.Dir, pattern))
+	if err != nil {
+		return fmt.Errorf("unable to get files in folder %s: %s", s.Dir, err)
+	}
+
+	readFilesInOrder(files, s.ParallelWorkers, func(filename string) []*Document {
+		log.Printf("start parsing %s", filename)
+
+		var documentsInFile []*Document
+		var err error
+		if s.SGML {
+			documentsInFile, err = collectDocuments(func(documents chan<- *Document) error {
+				return NewSGMLParser().ParseFile(filename, documents)
+			})
+		} else {
+			documentsInFile, err = parseReutersFile(filename)
+		}
+
+		if err != nil {
+			log.Printf("Unable to parse file %s: %s", filename, err)
+		} else {
+			log.Printf("finish parsing %s", filename)
+		}
+		return documentsInFile
+	}, documents)
+
+	return nil
+}
+
+// readFilesInOrder reads the files with parallel workers, but sends their
+// documents in the order of the files (and in the order read returns them
+// for each file), so that they get the same IDs on every run
+func readFilesInOrder(
+	files []string,
+	parallelWorkers int,
+	read func(filename string) []*Document,
+	documents chan<- *Document,
+) {
+	jobs := make(chan func() func())
+	go func() {
+		for i := range files {
+			filename := files[i]
+			jobs <- func() func() {
+				documentsInFile := read(filename)
+				return func() {
+					for _, document := range documentsInFile {
+						documents <- document
+					}
+				}
+			}
+		}
+		close(jobs)
+	}()
+
+	utils.ParallelOrdered(jobs, workers(parallelWorkers))
+}
+
+// collectDocuments returns all documents which parse sends to its channel
+func collectDocuments(parse func(documents chan<- *Document) error) ([]*Document, error) {
+	documents := make(chan *Document, 100)
+	collected := make(chan []*Document)
+
+	go func() {
+		var all []*Document
+		for document := range documents {
+			all = append(all, document)
+		}
+		collected <- all
+	}()
+
+	err := parse(documents)
+	close(documents)
+
+	return <-collected, err
+}
+
+// walkFiles returns all files under the directory which match the pattern,
+// sorted
+func walkFiles(dir string, pattern string) ([]string, error) {
+	var files []string
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		matches, err := filepath.Match(pattern, info.Name())
+		if err != nil {
+			return err
+		}
+		if matches {
+			files = append(files, path)
+		}
+		return nil
+	})
+
+	sort.Strings(files)
+	return files, err
+}
+
+func workers(parallelWorkers int) int {
+	if parallelWorkers < 1 {
+		return 1
+	}
+	return parallelWorkers
+}
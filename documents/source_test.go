@@ -0,0 +1,111 @@
+package documents
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readAll(t *testing.T, source Source) []*Document {
+	docs := make(chan *Document, 100)
+	assert.Nil(t, source.Read(docs))
+	close(docs)
+
+	var all []*Document
+	for doc := range docs {
+		all = append(all, doc)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	return all
+}
+
+func TestTextSource(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "text_source")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	write := func(name string, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.Nil(os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(ioutil.WriteFile(path, []byte(content), 0644))
+	}
+	write("sci.space/1.txt", "rockets")
+	write("comp/graphics/2.txt", "pixels")
+	write("3.txt", "nothing")
+	write("sci.space/ignored.dat", "binary")
+
+	docs := readAll(t, &TextSource{Dir: dir, ParallelWorkers: 2})
+	assert.Equal(3, len(docs))
+
+	assert.Equal("3.txt", docs[0].ID)
+	assert.Nil(docs[0].Classes)
+
+	assert.Equal("comp/graphics/2.txt", docs[1].ID)
+	assert.Equal([]string{"graphics"}, docs[1].Classes)
+
+	assert.Equal("sci.space/1.txt", docs[2].ID)
+	assert.Equal([]string{"sci.space"}, docs[2].Classes)
+	assert.Equal("rockets", docs[2].Body)
+}
+
+func TestJSONL(t *testing.T) {
+	assert := assert.New(t)
+
+	input := `{"id": "a", "title": "Oil", "body": "prices rose", "classes": ["crude"], "date": "1987-02-26"}
+
+{"title": "Wheat", "body": "harvest", "classes": ["grain", "wheat"]}
+not json
+`
+	docs := make(chan *Document, 10)
+	assert.Nil(readJSONL(strings.NewReader(input), "test.jsonl", docs))
+	close(docs)
+
+	a := <-docs
+	assert.Equal(&Document{ID: "a", Title: "Oil", Body: "prices rose", Classes: []string{"crude"}, Date: "1987-02-26"}, a)
+
+	b := <-docs
+	assert.Equal("3", b.ID)
+	assert.Equal([]string{"grain", "wheat"}, b.Classes)
+
+	_, ok := <-docs
+	assert.False(ok)
+}
+
+func TestCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	columns, err := ParseCSVColumns("title=headline, body=text")
+	assert.Nil(err)
+	assert.Equal("headline", columns.Title)
+	assert.Equal("classes", columns.Classes)
+
+	_, err = ParseCSVColumns("author=name")
+	assert.NotNil(err)
+
+	input := "headline,text,classes\nOil,\"prices, again\",crude; energy\nWheat,harvest,\n"
+
+	source := &CSVSource{Columns: columns}
+	docs := make(chan *Document, 10)
+	assert.Nil(source.read(strings.NewReader(input), docs))
+	close(docs)
+
+	oil := <-docs
+	assert.Equal(&Document{ID: "1", Title: "Oil", Body: "prices, again", Classes: []string{"crude", "energy"}}, oil)
+
+	wheat := <-docs
+	assert.Equal("2", wheat.ID)
+	assert.Nil(wheat.Classes)
+
+	// a typo in the mapping is an error rather than an empty field
+	columns, err = ParseCSVColumns("title=headlne, body=text")
+	assert.Nil(err)
+	source = &CSVSource{Columns: columns}
+	assert.NotNil(source.read(strings.NewReader(input), make(chan *Document, 10)))
+}
@@ -0,0 +1,279 @@
+package documents
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DexterLB/search/sampling"
+)
+
+// Subset is the part of a split a document belongs to
+type Subset int
+
+const (
+	Unused Subset = iota
+	Training
+	Test
+)
+
+// SplitMode is one of the standard Reuters-21578 training/test splits,
+// as defined in the README of the collection
+type SplitMode int
+
+const (
+	// ModApte uses documents with LEWISSPLIT TRAIN or TEST and TOPICS YES
+	ModApte SplitMode = iota
+	// ModLewis uses documents with LEWISSPLIT TRAIN or TEST and TOPICS YES
+	// or NO
+	ModLewis
+	// ModHayes uses CGISPLIT, and all documents
+	ModHayes
+)
+
+var splitModeNames = map[string]SplitMode{
+	"modapte":  ModApte,
+	"modlewis": ModLewis,
+	"modhayes": ModHayes,
+}
+
+// ParseSplitMode returns the split mode with the given name
+func ParseSplitMode(name string) (SplitMode, error) {
+	mode, ok := splitModeNames[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown split mode %s (available: modapte, modlewis, modhayes)", name)
+	}
+	return mode, nil
+}
+
+func (m SplitMode) String() string {
+	for name, mode := range splitModeNames {
+		if mode == m {
+			return name
+		}
+	}
+	return fmt.Sprintf("split mode(%d)", int(m))
+}
+
+// Assign returns the subset the document belongs to under this split
+func (m SplitMode) Assign(d *Document) Subset {
+	if m == ModHayes {
+		switch d.CGISplit {
+		case "TRAINING-SET":
+			return Training
+		case "PUBLISHED-TESTSET":
+			return Test
+		default:
+			return Unused
+		}
+	}
+
+	switch {
+	case d.Topics == "YES":
+	case d.Topics == "NO" && m == ModLewis:
+	default:
+		return Unused
+	}
+
+	switch d.LewisSplit {
+	case "TRAIN":
+		return Training
+	case "TEST":
+		return Test
+	default:
+		return Unused
+	}
+}
+
+// SplitDocuments sends the training and test documents to their channels
+// and drops the unused ones
+func (m SplitMode) SplitDocuments(docs <-chan *Document, training chan<- *Document, test chan<- *Document) {
+	for doc := range docs {
+		switch m.Assign(doc) {
+		case Training:
+			training <- doc
+		case Test:
+			test <- doc
+		}
+	}
+}
+
+// SplitMethod splits any corpus into a training and a test set, with a
+// given fraction of the documents in the test set
+type SplitMethod int
+
+const (
+	// RandomSplit picks the test documents at random
+	RandomSplit SplitMethod = iota
+	// StratifiedSplit picks the test documents so that every class (and
+	// the whole corpus) gets about the same fraction in the test set, with
+	// iterative stratification for documents with multiple classes
+	StratifiedSplit
+	// DateSplit picks the newest documents for the test set. Documents
+	// without a date are unused.
+	DateSplit
+)
+
+var splitMethodNames = map[string]SplitMethod{
+	"random":     RandomSplit,
+	"stratified": StratifiedSplit,
+	"date":       DateSplit,
+}
+
+// ParseSplitMethod returns the split method with the given name
+func ParseSplitMethod(name string) (SplitMethod, error) {
+	method, ok := splitMethodNames[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown split method %s (available: random, stratified, date)", name)
+	}
+	return method, nil
+}
+
+func (m SplitMethod) String() string {
+	for name, method := range splitMethodNames {
+		if method == m {
+			return name
+		}
+	}
+	return fmt.Sprintf("split method(%d)", int(m))
+}
+
+// Split assigns about testRatio of the documents to the test set and the
+// rest to the training set. The seed makes random and stratified splits
+// reproducible.
+func (m SplitMethod) Split(docs []*Document, testRatio float64, seed int64) []Subset {
+	proportions := []float64{1 - testRatio, testRatio}
+
+	switch m {
+	case StratifiedSplit:
+		return subsets(sampling.Stratify(classIDs(docs), proportions, seed))
+	case DateSplit:
+		return splitNewest(docs, testRatio)
+	default:
+		return subsets(sampling.Random(len(docs), proportions, seed))
+	}
+}
+
+// SplitByDate assigns the documents dated at or after the cutoff to the
+// test set, and the older ones to the training set. Documents without a
+// date are unused.
+func SplitByDate(docs []*Document, cutoff time.Time) []Subset {
+	assignment := make([]Subset, len(docs))
+	for i, doc := range docs {
+		date, err := ParseDate(doc.Date)
+		switch {
+		case err != nil:
+			assignment[i] = Unused
+		case date.Before(cutoff):
+			assignment[i] = Training
+		default:
+			assignment[i] = Test
+		}
+	}
+	return assignment
+}
+
+func splitNewest(docs []*Document, testRatio float64) []Subset {
+	assignment := make([]Subset, len(docs))
+	dates := make([]time.Time, len(docs))
+
+	var dated []int
+	for i, doc := range docs {
+		date, err := ParseDate(doc.Date)
+		if err != nil {
+			assignment[i] = Unused
+			continue
+		}
+		dates[i] = date
+		dated = append(dated, i)
+	}
+
+	sort.SliceStable(dated, func(a, b int) bool {
+		return dates[dated[a]].Before(dates[dated[b]])
+	})
+
+	training := len(dated) - int(math.Round(testRatio*float64(len(dated))))
+	for j, i := range dated {
+		if j < training {
+			assignment[i] = Training
+		} else {
+			assignment[i] = Test
+		}
+	}
+
+	return assignment
+}
+
+// classIDs numbers the classes of the documents in order of appearance
+func classIDs(docs []*Document) [][]int32 {
+	ids := make(map[string]int32)
+	classes := make([][]int32, len(docs))
+
+	for i, doc := range docs {
+		for _, class := range doc.Classes {
+			id, ok := ids[class]
+			if !ok {
+				id = int32(len(ids))
+				ids[class] = id
+			}
+			classes[i] = append(classes[i], id)
+		}
+	}
+
+	return classes
+}
+
+// subsets maps the subsets of the sampling package (0 and 1) to training
+// and test
+func subsets(assignment []int) []Subset {
+	result := make([]Subset, len(assignment))
+	for i, subset := range assignment {
+		if subset == 0 {
+			result[i] = Training
+		} else {
+			result[i] = Test
+		}
+	}
+	return result
+}
+
+// the date formats of the supported corpora: Reuters-21578, RCV1, emails
+// and newsgroups (handled by net/mail), and Wikipedia
+var dateLayouts = []string{
+	"2-Jan-2006 15:04:05.00",
+	"2-Jan-2006 15:04:05",
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+}
+
+// ParseDate parses the date of a document in any of the formats used by
+// the supported corpora
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	for _, layout := range dateLayouts {
+		date, err := time.Parse(layout, s)
+		if err == nil {
+			return date, nil
+		}
+	}
+
+	date, err := mail.ParseDate(s)
+	if err == nil {
+		return date, nil
+	}
+
+	// some Reuters dates have garbage after the day
+	if fields := strings.Fields(s); len(fields) > 1 {
+		date, err := time.Parse("2-Jan-2006", fields[0])
+		if err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unknown date format: %s", s)
+}
@@ -0,0 +1,111 @@
+package documents
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitModes(t *testing.T) {
+	assert := assert.New(t)
+
+	docs := []*Document{
+		{LewisSplit: "TRAIN", CGISplit: "TRAINING-SET", Topics: "YES"},
+		{LewisSplit: "TRAIN", CGISplit: "TRAINING-SET", Topics: "NO"},
+		{LewisSplit: "TEST", CGISplit: "PUBLISHED-TESTSET", Topics: "YES"},
+		{LewisSplit: "TEST", CGISplit: "TRAINING-SET", Topics: "BYPASS"},
+		{LewisSplit: "NOT-USED", CGISplit: "TRAINING-SET", Topics: "YES"},
+	}
+
+	expected := map[SplitMode][]Subset{
+		ModApte:  {Training, Unused, Test, Unused, Unused},
+		ModLewis: {Training, Training, Test, Unused, Unused},
+		ModHayes: {Training, Training, Test, Training, Training},
+	}
+
+	for mode, subsets := range expected {
+		for i, doc := range docs {
+			assert.Equal(subsets[i], mode.Assign(doc), "%s, document %d", mode, i)
+		}
+
+		parsed, err := ParseSplitMode(mode.String())
+		assert.Nil(err)
+		assert.Equal(mode, parsed)
+	}
+}
+
+func TestSplitMethods(t *testing.T) {
+	assert := assert.New(t)
+
+	var docs []*Document
+	for i := 0; i < 100; i++ {
+		class := "common"
+		if i%10 == 0 {
+			class = "rare"
+		}
+		docs = append(docs, &Document{
+			Classes: []string{class},
+			Date:    fmt.Sprintf("%d-MAR-1987 10:00:00.00", i%28+1),
+		})
+	}
+	docs[5].Date = ""
+
+	count := func(assignment []Subset, class string) map[Subset]int {
+		counts := make(map[Subset]int)
+		for i, subset := range assignment {
+			if class == "" || docs[i].Classes[0] == class {
+				counts[subset] += 1
+			}
+		}
+		return counts
+	}
+
+	random := RandomSplit.Split(docs, 0.2, 1)
+	assert.Equal(map[Subset]int{Training: 80, Test: 20}, count(random, ""))
+	assert.Equal(random, RandomSplit.Split(docs, 0.2, 1))
+
+	stratified := StratifiedSplit.Split(docs, 0.2, 1)
+	assert.Equal(map[Subset]int{Training: 8, Test: 2}, count(stratified, "rare"))
+	assert.Equal(map[Subset]int{Training: 72, Test: 18}, count(stratified, "common"))
+
+	newest := DateSplit.Split(docs, 0.2, 1)
+	assert.Equal(map[Subset]int{Training: 79, Test: 20, Unused: 1}, count(newest, ""))
+	for i, subset := range newest {
+		if subset == Test {
+			date, _ := ParseDate(docs[i].Date)
+			assert.True(date.Day() >= 22, "%s", docs[i].Date)
+		}
+	}
+
+	cutoff, err := ParseDate("1987-03-15")
+	assert.Nil(err)
+	byDate := SplitByDate(docs, cutoff)
+	assert.Equal(Unused, byDate[5])
+	assert.Equal(Training, byDate[13])
+	assert.Equal(Test, byDate[14])
+
+	parsed, err := ParseSplitMethod("stratified")
+	assert.Nil(err)
+	assert.Equal(StratifiedSplit, parsed)
+}
+
+func TestParseDate(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, date := range []string{
+		" 26-FEB-1987 15:01:01.79",
+		"26-FEB-1987 15:01:01",
+		"1987-02-26",
+		"1987-02-26T15:01:01Z",
+		"Thu, 26 Feb 1987 15:01:01 +0000",
+		"26-FEB-1987 garbage",
+	} {
+		parsed, err := ParseDate(date)
+		assert.Nil(err, date)
+		assert.Equal("1987-02-26", parsed.Format("2006-01-02"), date)
+	}
+
+	_, err := ParseDate("yesterday")
+	assert.NotNil(err)
+}
@@ -0,0 +1,65 @@
+package documents
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path"
+	"path/filepath"
+)
+
+// TextSource reads a directory tree of plain text files, where the class
+// of each file is the name of its parent directory (like 20 Newsgroups).
+// Files directly in the root directory have no class.
+type TextSource struct {
+	Dir             string
+	Pattern         string // of file names, "*.txt" if empty
+	ParallelWorkers int
+}
+
+func (s *TextSource) Read(documents chan<- *Document) error {
+	pattern := s.Pattern
+	if pattern == "" {
+		pattern = "*.txt"
+	}
+
+	files, err := walkFiles(s.Dir, pattern)
+	if err != nil {
+		return fmt.Errorf("unable to list files in %s: %s", s.Dir, err)
+	}
+
+	readFilesInOrder(files, s.ParallelWorkers, func(filename string) []*Document {
+		document, err := s.readFile(filename)
+		if err != nil {
+			log.Printf("%s", err)
+			return nil
+		}
+		return []*Document{document}
+	}, documents)
+
+	return nil
+}
+
+func (s *TextSource) readFile(filename string) (*Document, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %s", filename, err)
+	}
+
+	id, err := filepath.Rel(s.Dir, filename)
+	if err != nil {
+		id = filename
+	}
+	id = filepath.ToSlash(id)
+
+	document := &Document{
+		ID:   id,
+		Body: string(data),
+	}
+
+	if dir := path.Dir(id); dir != "." {
+		document.Classes = []string{path.Base(dir)}
+	}
+
+	return document, nil
+}
@@ -0,0 +1,186 @@
+package documents
+
+import (
+	"compress/bzip2"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/DexterLB/search/utils"
+)
+
+// WikipediaSource streams the articles of a Wikipedia pages-articles XML
+// dump (optionally compressed with bzip2), without reading it into memory.
+// Redirects and pages outside of the main namespace are skipped, the
+// wikitext markup is stripped, and the categories of each article become
+// its classes.
+type WikipediaSource struct {
+	Path            string
+	ParallelWorkers int
+}
+
+type wikiPage struct {
+	Title     string `xml:"title"`
+	Namespace int    `xml:"ns"`
+	ID        string `xml:"id"`
+	Redirect  *struct {
+		Title string `xml:"title,attr"`
+	} `xml:"redirect"`
+	Revision struct {
+		Timestamp string `xml:"timestamp"`
+		Text      string `xml:"text"`
+	} `xml:"revision"`
+}
+
+func (s *WikipediaSource) Read(documents chan<- *Document) error {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return fmt.Errorf("unable to open file: %s", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(s.Path, ".bz2") {
+		r = bzip2.NewReader(f)
+	}
+
+	pages := make(chan *wikiPage, 100)
+
+	// decoding is sequential, but stripping the markup is done in parallel,
+	// keeping the order of the pages
+	jobs := make(chan func() func())
+	go func() {
+		for page := range pages {
+			page := page
+			jobs <- func() func() {
+				document := WikipediaDocument(page.ID, page.Title, page.Revision.Timestamp, page.Revision.Text)
+				return func() {
+					documents <- document
+				}
+			}
+		}
+		close(jobs)
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		utils.ParallelOrdered(jobs, workers(s.ParallelWorkers))
+		close(done)
+	}()
+
+	err = readWikipediaPages(r, pages)
+	close(pages)
+	<-done
+
+	return err
+}
+
+// readWikipediaPages sends the articles of the dump which aren't
+// redirects to the channel
+func readWikipediaPages(r io.Reader, pages chan<- *wikiPage) error {
+	decoder := xml.NewDecoder(r)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "page" {
+			continue
+		}
+
+		page := &wikiPage{}
+		err = decoder.DecodeElement(page, &start)
+		if err != nil {
+			return err
+		}
+
+		if page.Namespace != 0 || page.Redirect != nil || isRedirect(page.Revision.Text) {
+			continue
+		}
+
+		pages <- page
+	}
+}
+
+func isRedirect(text string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(text)), "#REDIRECT")
+}
+
+var (
+	categoryRegex = regexp.MustCompile(`(?i)\[\[\s*category\s*:\s*([^\]|]+)(\|[^\]]*)?\]\]`)
+
+	commentRegex  = regexp.MustCompile(`(?s)<!--.*?-->`)
+	refRegex      = regexp.MustCompile(`(?is)<ref[^>/]*/>|<ref[^>]*>.*?</ref\s*>`)
+	templateRegex = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+	tableRegex    = regexp.MustCompile(`(?s)\{\|.*?\|\}`)
+	// links to files and categories, which may contain other links
+	fileLinkRegex = regexp.MustCompile(`(?i)\[\[\s*(file|image|category)\s*:[^\[\]]*(\[\[[^\[\]]*\]\][^\[\]]*)*\]\]`)
+	linkRegex     = regexp.MustCompile(`\[\[(?:[^\[\]|]*\|)?([^\[\]|]*)\]\]`)
+	externalRegex = regexp.MustCompile(`\[(?:https?|ftp)://[^\s\]]*\s*([^\]]*)\]`)
+	quotesRegex   = regexp.MustCompile(`'{2,}`)
+	headingRegex  = regexp.MustCompile(`(?m)^=+\s*(.*?)\s*=+\s*$`)
+	tagRegex      = regexp.MustCompile(`<[^>]*>`)
+	listRegex     = regexp.MustCompile(`(?m)^[*#:;]+\s*`)
+)
+
+// WikipediaDocument creates a document from an article, with the markup
+// stripped and its categories as classes
+func WikipediaDocument(id string, title string, date string, wikitext string) *Document {
+	document := &Document{
+		ID:    id,
+		Title: title,
+		Date:  date,
+	}
+
+	seen := make(map[string]bool)
+	for _, match := range categoryRegex.FindAllStringSubmatch(wikitext, -1) {
+		category := strings.TrimSpace(match[1])
+		if category != "" && !seen[category] {
+			seen[category] = true
+			document.Classes = append(document.Classes, category)
+		}
+	}
+
+	document.Body = StripWikitext(wikitext)
+	return document
+}
+
+// StripWikitext removes the markup from wikitext, keeping the text of
+// links and headings, and dropping templates, tables, references, files
+// and categories
+func StripWikitext(text string) string {
+	text = commentRegex.ReplaceAllString(text, "")
+	text = refRegex.ReplaceAllString(text, "")
+
+	// templates and tables nest, so remove the innermost ones until
+	// there are none left
+	for {
+		stripped := templateRegex.ReplaceAllString(text, "")
+		stripped = tableRegex.ReplaceAllString(stripped, "")
+		if stripped == text {
+			break
+		}
+		text = stripped
+	}
+
+	text = fileLinkRegex.ReplaceAllString(text, "")
+	text = linkRegex.ReplaceAllString(text, "$1")
+	text = externalRegex.ReplaceAllString(text, "$1")
+	text = quotesRegex.ReplaceAllString(text, "")
+	text = headingRegex.ReplaceAllString(text, "$1")
+	text = tagRegex.ReplaceAllString(text, "")
+	text = listRegex.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	return collapseSpace(text)
+}
@@ -0,0 +1,73 @@
+package documents
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDump = `<mediawiki xmlns="http://www.mediawiki.org/xml/export-0.10/" version="0.10" xml:lang="en">
+  <siteinfo><sitename>Wikipedia</sitename></siteinfo>
+  <page>
+    <title>Anarchism</title>
+    <ns>0</ns>
+    <id>12</id>
+    <revision>
+      <id>100</id>
+      <timestamp>2018-01-01T00:00:00Z</timestamp>
+      <text xml:space="preserve">{{Infobox|name={{nested|x}}}}
+'''Anarchism''' is a [[political philosophy]] that advocates [[self-governance|self-governed]] societies.&lt;ref&gt;Citation&lt;/ref&gt;
+
+== History ==
+* Early [http://example.com anarchists]
+[[File:Flag.svg|thumb|A [[flag]]]]
+{| class="wikitable"
+| cell
+|}
+&amp;nbsp;The end.&lt;!-- comment --&gt;
+
+[[Category:Anarchism]]
+[[Category:Political ideologies|Anarchism]]</text>
+    </revision>
+  </page>
+  <page>
+    <title>AccessibleComputing</title>
+    <ns>0</ns>
+    <id>10</id>
+    <redirect title="Computer accessibility" />
+    <revision><text>#REDIRECT [[Computer accessibility]]</text></revision>
+  </page>
+  <page>
+    <title>Talk:Anarchism</title>
+    <ns>1</ns>
+    <id>13</id>
+    <revision><text>Discussion</text></revision>
+  </page>
+</mediawiki>`
+
+func TestWikipedia(t *testing.T) {
+	assert := assert.New(t)
+
+	pages := make(chan *wikiPage, 10)
+	assert.Nil(readWikipediaPages(strings.NewReader(testDump), pages))
+	close(pages)
+
+	var all []*wikiPage
+	for page := range pages {
+		all = append(all, page)
+	}
+	assert.Equal(1, len(all))
+
+	page := all[0]
+	doc := WikipediaDocument(page.ID, page.Title, page.Revision.Timestamp, page.Revision.Text)
+	assert.Equal("12", doc.ID)
+	assert.Equal("Anarchism", doc.Title)
+	assert.Equal("2018-01-01T00:00:00Z", doc.Date)
+	assert.Equal([]string{"Anarchism", "Political ideologies"}, doc.Classes)
+	assert.Equal(
+		"Anarchism is a political philosophy that advocates self-governed societies.\n"+
+			"History\nEarly anarchists\nThe end.",
+		doc.Body,
+	)
+}
@@ -0,0 +1,235 @@
+package evaluation
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/DexterLB/search/trie"
+)
+
+// ClassPair is a cell of the confusion matrix
+type ClassPair struct {
+	Actual    int32
+	Predicted int32
+}
+
+// ConfusionMatrix counts how often each actual class co-occurs with each
+// predicted class. For a document, every actual class is paired with every
+// predicted class, except that a correctly predicted class is only paired
+// with itself (on the diagonal).
+type ConfusionMatrix struct {
+	ClassNames  *trie.BiDictionary
+	Counts      map[ClassPair]int
+	Examples    map[ClassPair][]string // names of misclassified documents
+	MaxExamples int
+}
+
+// Confusion is an off-diagonal cell of the confusion matrix
+type Confusion struct {
+	Actual    string   `json:"actual"`
+	Predicted string   `json:"predicted"`
+	Count     int      `json:"count"`
+	Examples  []string `json:"examples"`
+}
+
+func NewConfusionMatrix(classNames *trie.BiDictionary) *ConfusionMatrix {
+	return &ConfusionMatrix{
+		ClassNames:  classNames,
+		Counts:      make(map[ClassPair]int),
+		Examples:    make(map[ClassPair][]string),
+		MaxExamples: 5,
+	}
+}
+
+// Add records the classification of one document
+func (m *ConfusionMatrix) Add(name string, actual []int32, predicted []int32) {
+	actualSet := classSet(actual)
+	predictedSet := classSet(predicted)
+
+	for a := range actualSet {
+		if _, ok := predictedSet[a]; ok {
+			m.Counts[ClassPair{Actual: a, Predicted: a}] += 1
+			continue
+		}
+
+		for p := range predictedSet {
+			if _, ok := actualSet[p]; ok {
+				continue
+			}
+
+			pair := ClassPair{Actual: a, Predicted: p}
+			m.Counts[pair] += 1
+			if len(m.Examples[pair]) < m.MaxExamples {
+				m.Examples[pair] = append(m.Examples[pair], name)
+			}
+		}
+	}
+}
+
+// Merge adds the counts and examples of another matrix to this one
+func (m *ConfusionMatrix) Merge(other *ConfusionMatrix) {
+	for pair, count := range other.Counts {
+		m.Counts[pair] += count
+	}
+	for pair, examples := range other.Examples {
+		for _, example := range examples {
+			if len(m.Examples[pair]) < m.MaxExamples {
+				m.Examples[pair] = append(m.Examples[pair], example)
+			}
+		}
+	}
+}
+
+// MostConfused returns the n off-diagonal cells with the highest counts
+func (m *ConfusionMatrix) MostConfused(n int) []Confusion {
+	var pairs []ClassPair
+	for pair := range m.Counts {
+		if pair.Actual != pair.Predicted {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if m.Counts[pairs[i]] != m.Counts[pairs[j]] {
+			return m.Counts[pairs[i]] > m.Counts[pairs[j]]
+		}
+		if pairs[i].Actual != pairs[j].Actual {
+			return pairs[i].Actual < pairs[j].Actual
+		}
+		return pairs[i].Predicted < pairs[j].Predicted
+	})
+
+	if n < len(pairs) {
+		pairs = pairs[:n]
+	}
+
+	confusions := make([]Confusion, len(pairs))
+	for i, pair := range pairs {
+		confusions[i] = Confusion{
+			Actual:    m.className(pair.Actual),
+			Predicted: m.className(pair.Predicted),
+			Count:     m.Counts[pair],
+			Examples:  m.Examples[pair],
+		}
+	}
+	return confusions
+}
+
+// Classes returns the classes present in the matrix, the ones which appear
+// most often as actual classes first
+func (m *ConfusionMatrix) Classes() []int32 {
+	support := make(map[int32]int)
+	for pair, count := range m.Counts {
+		support[pair.Actual] += count
+		if _, ok := support[pair.Predicted]; !ok {
+			support[pair.Predicted] = 0
+		}
+	}
+
+	classes := make([]int32, 0, len(support))
+	for class := range support {
+		classes = append(classes, class)
+	}
+
+	sort.Slice(classes, func(i, j int) bool {
+		if support[classes[i]] != support[classes[j]] {
+			return support[classes[i]] > support[classes[j]]
+		}
+		return classes[i] < classes[j]
+	})
+
+	return classes
+}
+
+// WriteCSV writes the full matrix with actual classes as rows and predicted
+// classes as columns
+func (m *ConfusionMatrix) WriteCSV(w io.Writer) error {
+	classes := m.Classes()
+	writer := csv.NewWriter(w)
+
+	header := []string{"actual \\ predicted"}
+	for _, class := range classes {
+		header = append(header, m.className(class))
+	}
+	writer.Write(header)
+
+	for _, a := range classes {
+		row := []string{m.className(a)}
+		for _, p := range classes {
+			row = append(row, strconv.Itoa(m.Counts[ClassPair{Actual: a, Predicted: p}]))
+		}
+		writer.Write(row)
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// heatmap shades, from empty to the largest count in a row
+var shades = []rune(" ·░▒▓█")
+
+// WriteHeatmap draws the matrix for the maxClasses most frequent classes,
+// shading each cell relative to the largest count in its row
+func (m *ConfusionMatrix) WriteHeatmap(w io.Writer, maxClasses int) error {
+	classes := m.Classes()
+	if maxClasses < len(classes) {
+		classes = classes[:maxClasses]
+	}
+
+	_, err := fmt.Fprintf(w, "%-12s ", "")
+	if err != nil {
+		return err
+	}
+	for i := range classes {
+		if _, err = fmt.Fprintf(w, "%2d", i%100); err != nil {
+			return err
+		}
+	}
+	if _, err = fmt.Fprintln(w); err != nil {
+		return err
+	}
+
+	for i, a := range classes {
+		rowMax := 0
+		for _, p := range classes {
+			if count := m.Counts[ClassPair{Actual: a, Predicted: p}]; count > rowMax {
+				rowMax = count
+			}
+		}
+
+		row := []rune{}
+		for _, p := range classes {
+			count := m.Counts[ClassPair{Actual: a, Predicted: p}]
+			shade := shades[0]
+			if count > 0 {
+				shade = shades[1+(count*(len(shades)-2))/rowMax]
+			}
+			row = append(row, ' ', shade)
+		}
+
+		_, err = fmt.Fprintf(w, "%2d %-9.9s %s\n", i%100, m.className(a), string(row))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteConfusions lists the n most confused class pairs with examples
+func (m *ConfusionMatrix) WriteConfusions(w io.Writer, n int) error {
+	for _, c := range m.MostConfused(n) {
+		_, err := fmt.Fprintf(w, "%6d  %s -> %s  (e.g. %v)\n", c.Count, c.Actual, c.Predicted, c.Examples)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *ConfusionMatrix) className(class int32) string {
+	return className(m.ClassNames, class)
+}
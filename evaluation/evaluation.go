@@ -0,0 +1,281 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/DexterLB/search/trie"
+)
+
+// ClassCounts are the classification outcomes for a single class
+type ClassCounts struct {
+	TruePositives  int
+	FalsePositives int
+	FalseNegatives int
+}
+
+// Evaluation accumulates the results of multi-label classification
+type Evaluation struct {
+	ClassNames *trie.BiDictionary
+	Counts     []ClassCounts // indexed by class ID
+
+	Documents    int
+	ExactMatches int     // documents whose predicted classes are exactly right
+	JaccardSum   float64 // sum of |actual ∩ predicted| / |actual ∪ predicted|
+	LabelErrors  int     // sum of |actual △ predicted|
+
+	Confusion *ConfusionMatrix
+}
+
+func New(classNames *trie.BiDictionary) *Evaluation {
+	return &Evaluation{
+		ClassNames: classNames,
+		Confusion:  NewConfusionMatrix(classNames),
+	}
+}
+
+// Add records the classification of one document
+func (e *Evaluation) Add(name string, actual []int32, predicted []int32) {
+	e.Confusion.Add(name, actual, predicted)
+
+	actualSet := classSet(actual)
+	predictedSet := classSet(predicted)
+
+	intersection := 0
+	for class := range actualSet {
+		if _, ok := predictedSet[class]; ok {
+			e.counts(class).TruePositives += 1
+			intersection += 1
+		} else {
+			e.counts(class).FalseNegatives += 1
+		}
+	}
+
+	for class := range predictedSet {
+		if _, ok := actualSet[class]; !ok {
+			e.counts(class).FalsePositives += 1
+		}
+	}
+
+	union := len(actualSet) + len(predictedSet) - intersection
+	if union == 0 {
+		e.JaccardSum += 1
+	} else {
+		e.JaccardSum += float64(intersection) / float64(union)
+	}
+
+	if intersection == union {
+		e.ExactMatches += 1
+	}
+	e.LabelErrors += union - intersection
+	e.Documents += 1
+}
+
+// Merge adds the results of another evaluation to this one
+func (e *Evaluation) Merge(other *Evaluation) {
+	for class := range other.Counts {
+		counts := e.counts(int32(class))
+		counts.TruePositives += other.Counts[class].TruePositives
+		counts.FalsePositives += other.Counts[class].FalsePositives
+		counts.FalseNegatives += other.Counts[class].FalseNegatives
+	}
+
+	e.Documents += other.Documents
+	e.ExactMatches += other.ExactMatches
+	e.JaccardSum += other.JaccardSum
+	e.LabelErrors += other.LabelErrors
+	e.Confusion.Merge(other.Confusion)
+}
+
+func (e *Evaluation) counts(class int32) *ClassCounts {
+	for int(class) >= len(e.Counts) {
+		e.Counts = append(e.Counts, ClassCounts{})
+	}
+	return &e.Counts[class]
+}
+
+// ClassSummary holds the metrics of a single class
+type ClassSummary struct {
+	ClassID        int32   `json:"class_id"`
+	Class          string  `json:"class"`
+	Support        int     `json:"support"`
+	TruePositives  int     `json:"tp"`
+	FalsePositives int     `json:"fp"`
+	FalseNegatives int     `json:"fn"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+}
+
+// Summary holds all metrics of an evaluation
+type Summary struct {
+	Documents int `json:"documents"`
+
+	MicroPrecision float64 `json:"micro_precision"`
+	MicroRecall    float64 `json:"micro_recall"`
+	MicroF1        float64 `json:"micro_f1"`
+
+	MacroPrecision float64 `json:"macro_precision"`
+	MacroRecall    float64 `json:"macro_recall"`
+	MacroF1        float64 `json:"macro_f1"`
+
+	Accuracy       float64 `json:"accuracy"` // mean Jaccard index of actual and predicted classes
+	SubsetAccuracy float64 `json:"subset_accuracy"`
+	HammingLoss    float64 `json:"hamming_loss"`
+
+	Classes      []ClassSummary `json:"classes"` // sorted by support, largest first
+	MostConfused []Confusion    `json:"most_confused"`
+}
+
+// number of confused class pairs listed in a summary
+const summaryConfusions = 20
+
+// Summary computes the metrics. Macro averages are taken over the classes
+// which were either present or predicted at least once.
+func (e *Evaluation) Summary() *Summary {
+	s := &Summary{Documents: e.Documents}
+
+	var total ClassCounts
+	for class, counts := range e.Counts {
+		if counts.TruePositives+counts.FalsePositives+counts.FalseNegatives == 0 {
+			continue
+		}
+
+		total.TruePositives += counts.TruePositives
+		total.FalsePositives += counts.FalsePositives
+		total.FalseNegatives += counts.FalseNegatives
+
+		cs := ClassSummary{
+			ClassID:        int32(class),
+			Class:          e.className(int32(class)),
+			Support:        counts.TruePositives + counts.FalseNegatives,
+			TruePositives:  counts.TruePositives,
+			FalsePositives: counts.FalsePositives,
+			FalseNegatives: counts.FalseNegatives,
+		}
+		cs.Precision, cs.Recall, cs.F1 = scores(&counts)
+		s.Classes = append(s.Classes, cs)
+
+		s.MacroPrecision += cs.Precision
+		s.MacroRecall += cs.Recall
+		s.MacroF1 += cs.F1
+	}
+
+	if len(s.Classes) > 0 {
+		s.MacroPrecision /= float64(len(s.Classes))
+		s.MacroRecall /= float64(len(s.Classes))
+		s.MacroF1 /= float64(len(s.Classes))
+	}
+
+	s.MicroPrecision, s.MicroRecall, s.MicroF1 = scores(&total)
+
+	if e.Documents > 0 {
+		s.Accuracy = e.JaccardSum / float64(e.Documents)
+		s.SubsetAccuracy = float64(e.ExactMatches) / float64(e.Documents)
+
+		numClasses := len(e.Counts)
+		if e.ClassNames != nil && int(e.ClassNames.Size) > numClasses {
+			numClasses = int(e.ClassNames.Size)
+		}
+		if numClasses > 0 {
+			s.HammingLoss = float64(e.LabelErrors) / float64(e.Documents*numClasses)
+		}
+	}
+
+	sort.SliceStable(s.Classes, func(i, j int) bool {
+		return s.Classes[i].Support > s.Classes[j].Support
+	})
+
+	if e.Confusion != nil {
+		s.MostConfused = e.Confusion.MostConfused(summaryConfusions)
+	}
+
+	return s
+}
+
+func (e *Evaluation) className(class int32) string {
+	return className(e.ClassNames, class)
+}
+
+func className(classNames *trie.BiDictionary, class int32) string {
+	if classNames == nil {
+		return strconv.Itoa(int(class))
+	}
+	return string(classNames.GetInverse(class))
+}
+
+func scores(counts *ClassCounts) (precision float64, recall float64, f1 float64) {
+	if counts.TruePositives+counts.FalsePositives > 0 {
+		precision = float64(counts.TruePositives) / float64(counts.TruePositives+counts.FalsePositives)
+	}
+	if counts.TruePositives+counts.FalseNegatives > 0 {
+		recall = float64(counts.TruePositives) / float64(counts.TruePositives+counts.FalseNegatives)
+	}
+	if precision+recall > 0 {
+		f1 = 2 * precision * recall / (precision + recall)
+	}
+	return
+}
+
+func (s *Summary) String() string {
+	return fmt.Sprintf(
+		"micro P/R/F1: %.3f/%.3f/%.3f, macro P/R/F1: %.3f/%.3f/%.3f, accuracy: %.3f, subset accuracy: %.3f, hamming loss: %.4f",
+		s.MicroPrecision, s.MicroRecall, s.MicroF1,
+		s.MacroPrecision, s.MacroRecall, s.MacroF1,
+		s.Accuracy, s.SubsetAccuracy, s.HammingLoss,
+	)
+}
+
+// WriteTable writes the summary and the per-class metrics as a human
+// readable table
+func (s *Summary) WriteTable(w io.Writer) error {
+	_, err := fmt.Fprintf(w,
+		"documents:       %d\n"+
+			"micro precision: %.4f\nmicro recall:    %.4f\nmicro F1:        %.4f\n"+
+			"macro precision: %.4f\nmacro recall:    %.4f\nmacro F1:        %.4f\n"+
+			"accuracy:        %.4f\nsubset accuracy: %.4f\nhamming loss:    %.4f\n\n",
+		s.Documents,
+		s.MicroPrecision, s.MicroRecall, s.MicroF1,
+		s.MacroPrecision, s.MacroRecall, s.MacroF1,
+		s.Accuracy, s.SubsetAccuracy, s.HammingLoss,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%-20s %8s %6s %6s %6s %9s %9s %9s\n",
+		"class", "support", "tp", "fp", "fn", "precision", "recall", "f1")
+	if err != nil {
+		return err
+	}
+
+	for _, c := range s.Classes {
+		_, err = fmt.Fprintf(w, "%-20s %8d %6d %6d %6d %9.4f %9.4f %9.4f\n",
+			c.Class, c.Support, c.TruePositives, c.FalsePositives, c.FalseNegatives,
+			c.Precision, c.Recall, c.F1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Summary) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+func classSet(classes []int32) map[int32]struct{} {
+	set := make(map[int32]struct{})
+
+	for _, class := range classes {
+		set[class] = struct{}{}
+	}
+
+	return set
+}
@@ -0,0 +1,106 @@
+package evaluation
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/DexterLB/search/trie"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummary(t *testing.T) {
+	assert := assert.New(t)
+
+	classNames := trie.NewBiDictionary()
+	earn := classNames.Get([]byte("earn"))
+	acq := classNames.Get([]byte("acq"))
+	crude := classNames.Get([]byte("crude"))
+
+	e := New(classNames)
+	e.Add("doc", []int32{earn}, []int32{earn})        // exact
+	e.Add("doc", []int32{earn, acq}, []int32{earn})   // missed acq
+	e.Add("doc", []int32{crude}, []int32{acq})        // wrong
+	e.Add("doc", []int32{earn}, []int32{earn, crude}) // extra crude
+
+	s := e.Summary()
+
+	assert.Equal(4, s.Documents)
+
+	// tp: earn 3; fp: acq 1, crude 1; fn: acq 1, crude 1
+	assert.InDelta(3.0/5.0, s.MicroPrecision, 1e-9)
+	assert.InDelta(3.0/5.0, s.MicroRecall, 1e-9)
+	assert.InDelta(3.0/5.0, s.MicroF1, 1e-9)
+
+	// earn: P=1 R=1; acq: P=0 R=0; crude: P=0 R=0
+	assert.InDelta(1.0/3.0, s.MacroPrecision, 1e-9)
+	assert.InDelta(1.0/3.0, s.MacroRecall, 1e-9)
+	assert.InDelta(1.0/3.0, s.MacroF1, 1e-9)
+
+	assert.InDelta((1+0.5+0+0.5)/4.0, s.Accuracy, 1e-9)
+	assert.InDelta(1.0/4.0, s.SubsetAccuracy, 1e-9)
+	assert.InDelta(4.0/(4.0*3.0), s.HammingLoss, 1e-9)
+
+	assert.Equal("earn", s.Classes[0].Class)
+	assert.Equal(3, s.Classes[0].Support)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(s.WriteJSON(buf))
+	decoded := &Summary{}
+	assert.Nil(json.Unmarshal(buf.Bytes(), decoded))
+	assert.Equal(s, decoded)
+
+	buf.Reset()
+	assert.Nil(s.WriteTable(buf))
+	assert.Contains(buf.String(), "earn")
+}
+
+func TestMerge(t *testing.T) {
+	assert := assert.New(t)
+
+	a := New(nil)
+	a.Add("doc", []int32{0}, []int32{0})
+	b := New(nil)
+	b.Add("doc", []int32{1}, []int32{0})
+
+	both := New(nil)
+	both.Add("doc", []int32{0}, []int32{0})
+	both.Add("doc", []int32{1}, []int32{0})
+
+	a.Merge(b)
+	assert.Equal(both.Summary(), a.Summary())
+}
+
+func TestConfusion(t *testing.T) {
+	assert := assert.New(t)
+
+	classNames := trie.NewBiDictionary()
+	earn := classNames.Get([]byte("earn"))
+	acq := classNames.Get([]byte("acq"))
+	crude := classNames.Get([]byte("crude"))
+
+	e := New(classNames)
+	e.Add("a", []int32{earn}, []int32{earn})
+	e.Add("b", []int32{acq}, []int32{earn})
+	e.Add("c", []int32{acq, crude}, []int32{earn, crude})
+	e.Add("d", []int32{crude}, []int32{acq})
+
+	m := e.Confusion
+	assert.Equal(1, m.Counts[ClassPair{Actual: earn, Predicted: earn}])
+	assert.Equal(1, m.Counts[ClassPair{Actual: crude, Predicted: crude}])
+	assert.Equal(2, m.Counts[ClassPair{Actual: acq, Predicted: earn}])
+	assert.Equal(1, m.Counts[ClassPair{Actual: crude, Predicted: acq}])
+	// crude was predicted correctly in c, so it isn't confused with earn
+	assert.Equal(0, m.Counts[ClassPair{Actual: crude, Predicted: earn}])
+
+	confused := m.MostConfused(1)
+	assert.Equal([]Confusion{{Actual: "acq", Predicted: "earn", Count: 2, Examples: []string{"b", "c"}}}, confused)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(m.WriteCSV(buf))
+	assert.Contains(buf.String(), "acq,0,0,2\n")
+
+	buf.Reset()
+	assert.Nil(m.WriteHeatmap(buf, 2))
+	assert.Equal(3, bytes.Count(buf.Bytes(), []byte("\n")))
+}
@@ -0,0 +1,89 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Statistic is the mean and standard deviation of a metric over several
+// evaluations, e.g. the folds of a cross-validation
+type Statistic struct {
+	Metric string  `json:"metric"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+}
+
+// Metrics returns the scalar metrics of the summary by name, in a fixed
+// order
+func (s *Summary) Metrics() []Statistic {
+	return []Statistic{
+		{Metric: "micro precision", Mean: s.MicroPrecision},
+		{Metric: "micro recall", Mean: s.MicroRecall},
+		{Metric: "micro F1", Mean: s.MicroF1},
+		{Metric: "macro precision", Mean: s.MacroPrecision},
+		{Metric: "macro recall", Mean: s.MacroRecall},
+		{Metric: "macro F1", Mean: s.MacroF1},
+		{Metric: "accuracy", Mean: s.Accuracy},
+		{Metric: "subset accuracy", Mean: s.SubsetAccuracy},
+		{Metric: "hamming loss", Mean: s.HammingLoss},
+	}
+}
+
+// Statistics computes the mean and (sample) standard deviation of every
+// scalar metric over the given summaries
+func Statistics(summaries []*Summary) []Statistic {
+	if len(summaries) == 0 {
+		return nil
+	}
+
+	stats := summaries[0].Metrics()
+	for i := range stats {
+		stats[i].Mean = 0
+	}
+
+	for _, summary := range summaries {
+		for i, metric := range summary.Metrics() {
+			stats[i].Mean += metric.Mean
+		}
+	}
+	for i := range stats {
+		stats[i].Mean /= float64(len(summaries))
+	}
+
+	if len(summaries) > 1 {
+		for _, summary := range summaries {
+			for i, metric := range summary.Metrics() {
+				stats[i].StdDev += square(metric.Mean - stats[i].Mean)
+			}
+		}
+		for i := range stats {
+			stats[i].StdDev = math.Sqrt(stats[i].StdDev / float64(len(summaries)-1))
+		}
+	}
+
+	return stats
+}
+
+// WriteStatistics writes the statistics as "metric: mean ± stddev" lines
+func WriteStatistics(w io.Writer, stats []Statistic) error {
+	for _, stat := range stats {
+		_, err := fmt.Fprintf(w, "%-16s %.4f ± %.4f\n", stat.Metric+":", stat.Mean, stat.StdDev)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteStatisticsJSON writes the statistics as a JSON array
+func WriteStatisticsJSON(w io.Writer, stats []Statistic) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(stats)
+}
+
+func square(x float64) float64 {
+	return x * x
+}
@@ -0,0 +1,115 @@
+package featureselection
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/DexterLB/search/indices"
+)
+
+// Aggregation selects how per-class term scores are turned into a single
+// feature set
+type Aggregation int32
+
+const (
+	// RoundRobin takes the best remaining term of each class in turn,
+	// up to a fixed number of terms per class
+	RoundRobin Aggregation = iota
+	// Max scores each term by its best score over all classes
+	Max
+	// WeightedAverage scores each term by its average score over all
+	// classes, weighted by the class prior
+	WeightedAverage
+)
+
+var aggregationNames = map[string]Aggregation{
+	"round-robin": RoundRobin,
+	"max":         Max,
+	"avg":         WeightedAverage,
+}
+
+// ParseAggregation returns the aggregation strategy with the given name
+func ParseAggregation(name string) (Aggregation, error) {
+	aggregation, ok := aggregationNames[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown aggregation %s (available: round-robin, max, avg)", name)
+	}
+	return aggregation, nil
+}
+
+func (a Aggregation) String() string {
+	for name, aggregation := range aggregationNames {
+		if aggregation == a {
+			return name
+		}
+	}
+	return fmt.Sprintf("aggregation(%d)", int32(a))
+}
+
+// SelectGlobal picks the best totalTerms terms by their global score, as
+// combined from all classes by the aggregation strategy
+func SelectGlobal(ti *indices.TotalIndex, scorer Scorer, aggregation Aggregation, totalTerms int32, parallelWorkers int) []int32 {
+	info := ComputeClassInfo(ti)
+	table := ScoreTable(ti, info, scorer, parallelWorkers)
+	return TopGlobalTerms(GlobalScores(table, info, aggregation), totalTerms)
+}
+
+// GlobalScores combines a score table as returned by ScoreTable (not sorted)
+// into a single score for each term
+func GlobalScores(table [][]TermScore, ci *ClassInfo, aggregation Aggregation) []TermScore {
+	if len(table) == 0 {
+		return nil
+	}
+
+	scores := make([]TermScore, len(table[0]))
+	for termID := range scores {
+		scores[termID].TermID = int32(termID)
+		if aggregation == Max {
+			scores[termID].Score = math.Inf(-1)
+		}
+	}
+
+	for classID := range table {
+		prior := float64(ci.DocumentsWhichHaveClass[classID]) / float64(ci.NumDocuments)
+
+		for termID, termScore := range table[classID] {
+			switch aggregation {
+			case Max:
+				scores[termID].Score = math.Max(scores[termID].Score, termScore.Score)
+			case WeightedAverage:
+				if prior > 0 {
+					scores[termID].Score += prior * termScore.Score
+				}
+			default:
+				panic(fmt.Sprintf("%s is not a global aggregation", aggregation))
+			}
+		}
+	}
+
+	return scores
+}
+
+// TopGlobalTerms returns the IDs of the totalTerms best scored terms,
+// in ascending order. Terms scored -Inf are never picked.
+func TopGlobalTerms(scores []TermScore, totalTerms int32) []int32 {
+	sorted := append([]TermScore(nil), scores...)
+	sortScores(sorted)
+	for len(sorted) > 0 && math.IsInf(sorted[len(sorted)-1].Score, -1) {
+		sorted = sorted[:len(sorted)-1]
+	}
+
+	if int(totalTerms) < len(sorted) {
+		sorted = sorted[:totalTerms]
+	}
+
+	terms := make([]int32, len(sorted))
+	for i := range sorted {
+		terms[i] = sorted[i].TermID
+	}
+
+	sort.Slice(terms, func(i, j int) bool { return terms[i] < terms[j] })
+
+	return terms
+}
@@ -0,0 +1,27 @@
+package featureselection
+
+import "math/bits"
+
+// bitset is a set of small non-negative integers
+type bitset []uint64
+
+func newBitset(size int32) bitset {
+	return make(bitset, (size+63)/64)
+}
+
+func (b bitset) set(i int32) {
+	b[i/64] |= 1 << uint(i%64)
+}
+
+func (b bitset) has(i int32) bool {
+	return b[i/64]&(1<<uint(i%64)) != 0
+}
+
+// forEach calls operation for every element of the set in ascending order
+func (b bitset) forEach(operation func(i int32)) {
+	for word := range b {
+		for word64 := b[word]; word64 != 0; word64 &= word64 - 1 {
+			operation(int32(word)*64 + int32(bits.TrailingZeros64(word64)))
+		}
+	}
+}
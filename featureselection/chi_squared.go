@@ -1,6 +1,7 @@
 package featureselection
 
 import (
+	"math"
 	"sort"
 
 	"github.com/DexterLB/search/indices"
@@ -13,12 +14,21 @@ type TermScore struct {
 }
 
 func ChiSquared(ti *indices.TotalIndex, termsPerClass int32, parallelWorkers int) []int32 {
+	return Select(ti, ChiSquaredScore, termsPerClass, parallelWorkers)
+}
+
+// Select picks the best termsPerClass terms for each class according to
+// the scorer
+func Select(ti *indices.TotalIndex, scorer Scorer, termsPerClass int32, parallelWorkers int) []int32 {
 	info := ComputeClassInfo(ti)
-	table := SortedChiSquaredTable(ti, info, parallelWorkers)
-	return TopChiSquaredTerms(table, termsPerClass)
+	table := SortedScoreTable(ti, info, scorer, parallelWorkers)
+	return TopTerms(table, termsPerClass)
 }
 
-func TopChiSquaredTerms(table [][]TermScore, termsPerClass int32) []int32 {
+// TopTerms picks terms from a sorted score table, taking the best term
+// not already taken from each class in turn. Terms scored -Inf are never
+// picked.
+func TopTerms(table [][]TermScore, termsPerClass int32) []int32 {
 	termSet := make(map[int32]struct{})
 	termIndices := make([]int32, len(table))
 	termsFromClass := make([]int32, len(table))
@@ -30,6 +40,12 @@ func TopChiSquaredTerms(table [][]TermScore, termsPerClass int32) []int32 {
 			for ; termIndices[classIndex] < int32(len(table[classIndex])) && termsFromClass[classIndex] < termsPerClass; termIndices[classIndex] += 1 {
 				termIndex := termIndices[classIndex]
 
+				if math.IsInf(table[classIndex][termIndex].Score, -1) {
+					// the rest of the class is -Inf too
+					termIndices[classIndex] = int32(len(table[classIndex]))
+					break
+				}
+
 				termID := table[classIndex][termIndex].TermID
 				if _, ok := termSet[termID]; !ok {
 					termSet[termID] = struct{}{}
@@ -53,8 +69,21 @@ func TopChiSquaredTerms(table [][]TermScore, termsPerClass int32) []int32 {
 	return sortedTerms
 }
 
+// TopChiSquaredTerms picks terms from a sorted score table like TopTerms.
+//
+// Deprecated: use TopTerms, which works with the tables of any scorer.
+func TopChiSquaredTerms(table [][]TermScore, termsPerClass int32) []int32 {
+	return TopTerms(table, termsPerClass)
+}
+
 func SortedChiSquaredTable(ti *indices.TotalIndex, ci *ClassInfo, parallelWorkers int) [][]TermScore {
-	table := make([][]TermScore, ci.NumClasses)
+	return SortedScoreTable(ti, ci, ChiSquaredScore, parallelWorkers)
+}
+
+// SortedScoreTable scores every term for every class, and sorts the terms of
+// each class from best to worst
+func SortedScoreTable(ti *indices.TotalIndex, ci *ClassInfo, scorer Scorer, parallelWorkers int) [][]TermScore {
+	table := ScoreTable(ti, ci, scorer, parallelWorkers)
 
 	work := make(chan int32, 2000)
 	go func() {
@@ -64,16 +93,57 @@ func SortedChiSquaredTable(ti *indices.TotalIndex, ci *ClassInfo, parallelWorker
 		close(work)
 	}()
 
+	utils.Parallel(func() {
+		for classID := range work {
+			sortScores(table[classID])
+		}
+	}, parallelWorkers)
+
+	return table
+}
+
+// ScoreTable scores every term for every class, so that table[classID][termID]
+// is the score of the term for the class. It makes a single pass over the
+// inverse index, counting N11 for all classes of each posting's document at
+// once, so it takes O(postings × classes per document) plus the time to
+// score the table.
+func ScoreTable(ti *indices.TotalIndex, ci *ClassInfo, scorer Scorer, parallelWorkers int) [][]TermScore {
 	numTerms := int32(len(ti.Inverse.PostingLists))
 
+	table := make([][]TermScore, ci.NumClasses)
+	for classID := range table {
+		table[classID] = make([]TermScore, numTerms)
+	}
+
+	work := make(chan int32, 2000)
+	go func() {
+		for termID := int32(0); termID < numTerms; termID++ {
+			work <- termID
+		}
+		close(work)
+	}()
+
 	utils.Parallel(func() {
-		for classID := range work {
-			table[classID] = make([]TermScore, numTerms)
-			for termID := int32(0); termID < numTerms; termID++ {
-				table[classID][termID].TermID = termID
-				table[classID][termID].Score = ChiSquaredForTermAndClass(ti, ci, termID, classID)
+		n11 := make([]float64, ci.NumClasses)
+		c := &Contingency{}
+
+		for termID := range work {
+			documentFrequency := float64(0)
+			ti.LoopOverTermPostings(int(termID), func(posting *indices.Posting) {
+				documentFrequency += 1
+				ci.documentClasses[posting.Index].forEach(func(class int32) {
+					n11[class] += 1
+				})
+			})
+
+			for classID := range table {
+				c.N11 = n11[classID]
+				c.N01 = documentFrequency - n11[classID]
+				c.fill(ci, int32(classID))
+
+				table[classID][termID] = TermScore{TermID: termID, Score: scorer(c)}
+				n11[classID] = 0
 			}
-			sortScores(table[classID])
 		}
 	}, parallelWorkers)
 
@@ -81,7 +151,12 @@ func SortedChiSquaredTable(ti *indices.TotalIndex, ci *ClassInfo, parallelWorker
 }
 
 func sortScores(scores []TermScore) {
-	sort.Slice(scores, func(i, j int) bool { return scores[i].Score > scores[j].Score })
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].TermID < scores[j].TermID
+	})
 }
 
 func ChiSquaredForClass(ti *indices.TotalIndex, ci *ClassInfo, classID int32) []float64 {
@@ -94,48 +169,7 @@ func ChiSquaredForClass(ti *indices.TotalIndex, ci *ClassInfo, classID int32) []
 }
 
 func ChiSquaredForTermAndClass(ti *indices.TotalIndex, ci *ClassInfo, termID int32, classID int32) float64 {
-	numDocuments := int32(len(ti.Forward.PostingLists))
-
-	var N00 int32 // Documents which DON'T contain the term and DON'T have the class
-	var N01 int32 // Documents which DO    contain the term and DON'T have the class
-	var N10 int32 // Documents which DON'T contain the term and DO    have the class
-	var N11 int32 // Documents which DO    contain the term and DO    have the class
-
-	ti.LoopOverTermPostings(int(termID), func(posting *indices.Posting) {
-		if docHasClass(ti, posting.Index, classID) {
-			N11 += 1
-		} else {
-			N01 += 1
-		}
-	})
-
-	N10 = ci.DocumentsWhichHaveClass[classID] - N11
-	N00 = numDocuments - N01 - N10 + N11
-
-	N := float64(numDocuments)
-
-	E11 := (float64(N11+N10) * float64(N11+N01)) / N
-	E01 := (float64(N01+N00) * float64(N11+N01)) / N
-	E10 := (float64(N11+N10) * float64(N10+N00)) / N
-	E00 := (float64(N01+N00) * float64(N10+N00)) / N
-
-	M00 := square(float64(N00)-E00) / N
-	M01 := square(float64(N01)-E01) / N
-	M10 := square(float64(N10)-E10) / N
-	M11 := square(float64(N11)-E11) / N
-
-	result := M00 + M01 + M10 + M11
-
-	return result
-}
-
-func docHasClass(ti *indices.TotalIndex, docID int32, classID int32) bool {
-	for _, class := range ti.Documents[docID].Classes {
-		if class == classID {
-			return true
-		}
-	}
-	return false
+	return ChiSquaredScore(ContingencyForTermAndClass(ti, ci, termID, classID))
 }
 
 func square(x float64) float64 {
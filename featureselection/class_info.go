@@ -6,6 +6,10 @@ type ClassInfo struct {
 	DocumentsWhichHaveClass   []int32
 	DocumentsWhichContainTerm []int32
 	NumClasses                int32
+	NumDocuments              int32
+
+	// classes of each document
+	documentClasses []bitset
 }
 
 func ComputeClassInfo(ti *indices.TotalIndex) *ClassInfo {
@@ -17,6 +21,8 @@ func ComputeClassInfo(ti *indices.TotalIndex) *ClassInfo {
 	info.NumClasses = numClasses
 	info.DocumentsWhichHaveClass = make([]int32, numClasses)
 	info.DocumentsWhichContainTerm = make([]int32, numTerms)
+	info.NumDocuments = int32(len(ti.Forward.PostingLists))
+	info.documentClasses = make([]bitset, info.NumDocuments)
 
 	for termID := range ti.Inverse.PostingLists {
 		ti.LoopOverTermPostings(termID, func(posting *indices.Posting) {
@@ -25,9 +31,14 @@ func ComputeClassInfo(ti *indices.TotalIndex) *ClassInfo {
 	}
 
 	for docID := range ti.Forward.PostingLists {
+		info.documentClasses[docID] = newBitset(numClasses)
 		for _, class := range ti.Documents[docID].Classes {
-			info.DocumentsWhichHaveClass[class] += 1
+			info.documentClasses[docID].set(class)
 		}
+
+		info.documentClasses[docID].forEach(func(class int32) {
+			info.DocumentsWhichHaveClass[class] += 1
+		})
 	}
 
 	return info
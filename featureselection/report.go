@@ -0,0 +1,56 @@
+package featureselection
+
+import "github.com/DexterLB/search/indices"
+
+// TermReport describes how good a term is as a feature for a class
+type TermReport struct {
+	TermID                 int32   `json:"term_id"`
+	Term                   string  `json:"term"`
+	Score                  float64 `json:"score"`
+	DocumentFrequency      int32   `json:"df"`       // documents which contain the term
+	ClassDocumentFrequency int32   `json:"class_df"` // documents of the class which contain the term
+}
+
+// ClassReport lists the best features of a class
+type ClassReport struct {
+	ClassID   int32        `json:"class_id"`
+	Class     string       `json:"class"`
+	Documents int32        `json:"documents"`
+	Terms     []TermReport `json:"terms"`
+}
+
+// Report scores all terms with the scorer and describes the best
+// termsPerClass of them for each class (none if termsPerClass is negative)
+func Report(ti *indices.TotalIndex, scorer Scorer, termsPerClass int32, parallelWorkers int) []ClassReport {
+	info := ComputeClassInfo(ti)
+	table := SortedScoreTable(ti, info, scorer, parallelWorkers)
+
+	reports := make([]ClassReport, len(table))
+	for classID := range table {
+		report := &reports[classID]
+		report.ClassID = int32(classID)
+		report.Class = string(ti.ClassNames.GetInverse(int32(classID)))
+		report.Documents = info.DocumentsWhichHaveClass[classID]
+
+		scores := table[classID]
+		if termsPerClass < 0 {
+			scores = nil
+		} else if int(termsPerClass) < len(scores) {
+			scores = scores[:termsPerClass]
+		}
+
+		report.Terms = make([]TermReport, len(scores))
+		for i, termScore := range scores {
+			c := ContingencyForTermAndClass(ti, info, termScore.TermID, int32(classID))
+			report.Terms[i] = TermReport{
+				TermID:                 termScore.TermID,
+				Term:                   string(ti.Dictionary.GetInverse(termScore.TermID)),
+				Score:                  termScore.Score,
+				DocumentFrequency:      info.DocumentsWhichContainTerm[termScore.TermID],
+				ClassDocumentFrequency: int32(c.N11),
+			}
+		}
+	}
+
+	return reports
+}
@@ -0,0 +1,170 @@
+package featureselection
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/DexterLB/search/indices"
+)
+
+// Contingency holds the document counts of the 2x2 table for a term and
+// a class
+type Contingency struct {
+	N00 float64 // Documents which DON'T contain the term and DON'T have the class
+	N01 float64 // Documents which DO    contain the term and DON'T have the class
+	N10 float64 // Documents which DON'T contain the term and DO    have the class
+	N11 float64 // Documents which DO    contain the term and DO    have the class
+}
+
+func (c *Contingency) N() float64 {
+	return c.N00 + c.N01 + c.N10 + c.N11
+}
+
+// Scorer rates how good a term is as a feature for a class. Higher is better.
+type Scorer func(c *Contingency) float64
+
+var scorers = map[string]Scorer{
+	"chi2": ChiSquaredScore,
+	"mi":   MutualInformationScore,
+	"ig":   InformationGainScore,
+	"or":   OddsRatioScore,
+	"gss":  GSSScore,
+	"bns":  BiNormalSeparationScore,
+	"df":   DocumentFrequencyScore,
+}
+
+// ParseScorer returns the scorer with the given name
+func ParseScorer(name string) (Scorer, error) {
+	scorer, ok := scorers[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown feature scorer %s (available: %s)", name, strings.Join(ScorerNames(), ", "))
+	}
+	return scorer, nil
+}
+
+// ScorerNames lists the names accepted by ParseScorer
+func ScorerNames() []string {
+	var names []string
+	for name := range scorers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func ContingencyForTermAndClass(ti *indices.TotalIndex, ci *ClassInfo, termID int32, classID int32) *Contingency {
+	c := &Contingency{}
+
+	ti.LoopOverTermPostings(int(termID), func(posting *indices.Posting) {
+		if ci.documentClasses[posting.Index].has(classID) {
+			c.N11 += 1
+		} else {
+			c.N01 += 1
+		}
+	})
+
+	c.fill(ci, classID)
+
+	return c
+}
+
+// fill computes the remaining counts once N11 and N01 are known
+func (c *Contingency) fill(ci *ClassInfo, classID int32) {
+	c.N10 = float64(ci.DocumentsWhichHaveClass[classID]) - c.N11
+	c.N00 = float64(ci.NumDocuments) - c.N01 - c.N10 - c.N11
+}
+
+func ChiSquaredScore(c *Contingency) float64 {
+	N := c.N()
+
+	E11 := ((c.N11 + c.N10) * (c.N11 + c.N01)) / N
+	E01 := ((c.N01 + c.N00) * (c.N11 + c.N01)) / N
+	E10 := ((c.N11 + c.N10) * (c.N10 + c.N00)) / N
+	E00 := ((c.N01 + c.N00) * (c.N10 + c.N00)) / N
+
+	M00 := square(c.N00-E00) / N
+	M01 := square(c.N01-E01) / N
+	M10 := square(c.N10-E10) / N
+	M11 := square(c.N11-E11) / N
+
+	return M00 + M01 + M10 + M11
+}
+
+// MutualInformationScore is the pointwise mutual information of the term
+// and the class, as used by Yang & Pedersen, with 0.5 added to each count
+// so that terms missing from a class get a finite score which can be
+// averaged over the classes
+func MutualInformationScore(c *Contingency) float64 {
+	return math.Log2((c.N() + 2) * (c.N11 + 0.5) / ((c.N11 + c.N01 + 1) * (c.N11 + c.N10 + 1)))
+}
+
+// InformationGainScore is the expected mutual information of the term
+// occurrence and the class membership
+func InformationGainScore(c *Contingency) float64 {
+	N := c.N()
+	cell := func(nij float64, termTotal float64, classTotal float64) float64 {
+		if nij == 0 {
+			return 0
+		}
+		return nij / N * math.Log2(N*nij/(termTotal*classTotal))
+	}
+
+	return cell(c.N11, c.N11+c.N01, c.N11+c.N10) +
+		cell(c.N01, c.N11+c.N01, c.N01+c.N00) +
+		cell(c.N10, c.N10+c.N00, c.N11+c.N10) +
+		cell(c.N00, c.N10+c.N00, c.N01+c.N00)
+}
+
+// OddsRatioScore is the log odds ratio, with 0.5 added to each count so that
+// empty cells don't produce infinities
+func OddsRatioScore(c *Contingency) float64 {
+	return math.Log(((c.N11 + 0.5) * (c.N00 + 0.5)) / ((c.N01 + 0.5) * (c.N10 + 0.5)))
+}
+
+// GSSScore is the Galavotti-Sebastiani-Simi coefficient
+func GSSScore(c *Contingency) float64 {
+	N := c.N()
+	return (c.N11*c.N00 - c.N01*c.N10) / (N * N)
+}
+
+// BiNormalSeparationScore is Forman's bi-normal separation: the distance
+// between the inverse normal CDFs of the true and false positive rates
+func BiNormalSeparationScore(c *Contingency) float64 {
+	tpr := clamp(c.N11/(c.N11+c.N10), 0.0005, 0.9995)
+	fpr := clamp(c.N01/(c.N01+c.N00), 0.0005, 0.9995)
+	return math.Abs(inverseNormalCDF(tpr) - inverseNormalCDF(fpr))
+}
+
+// DocumentFrequencyScore is the number of documents which contain the term,
+// regardless of class, so selecting by it keeps the most frequent terms. To
+// drop rare terms with any scorer, use WithMinDocumentFrequency.
+func DocumentFrequencyScore(c *Contingency) float64 {
+	return c.N11 + c.N01
+}
+
+// WithMinDocumentFrequency wraps the scorer so that terms which are in fewer
+// than min documents score -Inf, which means that they are never selected
+func WithMinDocumentFrequency(scorer Scorer, min int) Scorer {
+	return func(c *Contingency) float64 {
+		if c.N11+c.N01 < float64(min) {
+			return math.Inf(-1)
+		}
+		return scorer(c)
+	}
+}
+
+func inverseNormalCDF(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+func clamp(x float64, min float64, max float64) float64 {
+	if math.IsNaN(x) || x < min {
+		return min
+	}
+	if x > max {
+		return max
+	}
+	return x
+}
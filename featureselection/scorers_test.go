@@ -0,0 +1,222 @@
+package featureselection
+
+import (
+	"math"
+	"testing"
+
+	"github.com/DexterLB/search/indices"
+	"github.com/stretchr/testify/assert"
+)
+
+func testIndex() *indices.TotalIndex {
+	ti := indices.NewTotalIndex()
+
+	add := func(classes []string, terms ...string) {
+		doc := indices.NewInfoAndTerms()
+		doc.Classes = classes
+		for _, term := range terms {
+			doc.TermsAndCounts.Put([]byte(term), 1)
+			doc.Length += 1
+		}
+		ti.Add(doc)
+	}
+
+	add([]string{"sports"}, "ball", "the")
+	add([]string{"sports"}, "ball", "goal", "the")
+	add([]string{"sports", "politics"}, "ball", "vote", "the")
+	add([]string{"politics"}, "vote", "the")
+	add([]string{"politics"}, "vote", "party", "the")
+	add([]string{"economy"}, "oil", "the")
+
+	return ti
+}
+
+func TestContingency(t *testing.T) {
+	assert := assert.New(t)
+
+	ti := testIndex()
+	ci := ComputeClassInfo(ti)
+
+	ball := ti.Dictionary.Get([]byte("ball"))
+	sports := ti.ClassNames.Get([]byte("sports"))
+
+	assert.Equal(
+		&Contingency{N00: 3, N01: 0, N10: 0, N11: 3},
+		ContingencyForTermAndClass(ti, ci, ball, sports),
+	)
+
+	politics := ti.ClassNames.Get([]byte("politics"))
+	assert.Equal(
+		&Contingency{N00: 1, N01: 2, N10: 2, N11: 1},
+		ContingencyForTermAndClass(ti, ci, ball, politics),
+	)
+}
+
+func TestChiSquaredScore(t *testing.T) {
+	assert := assert.New(t)
+
+	// 10 documents, 4 of them in the class, 5 of them with the term
+	c := &Contingency{N11: 3, N01: 2}
+	c.fill(&ClassInfo{NumDocuments: 10, DocumentsWhichHaveClass: []int32{4}}, 0)
+	assert.Equal(&Contingency{N00: 4, N01: 2, N10: 1, N11: 3}, c)
+
+	// all expected counts are 10 * 0.5 * (0.4 or 0.6), i.e. 2 or 3, so each
+	// cell is off by one, and contributes 1/N
+	assert.InDelta(0.4, ChiSquaredScore(c), 1e-9)
+}
+
+func TestScorersPreferClassSpecificTerms(t *testing.T) {
+	assert := assert.New(t)
+
+	ti := testIndex()
+	ci := ComputeClassInfo(ti)
+
+	sports := ti.ClassNames.Get([]byte("sports"))
+	ball := ti.Dictionary.Get([]byte("ball"))
+	party := ti.Dictionary.Get([]byte("party"))
+
+	for _, name := range ScorerNames() {
+		if name == "df" {
+			continue
+		}
+
+		scorer, err := ParseScorer(name)
+		assert.Nil(err)
+
+		assert.True(
+			scorer(ContingencyForTermAndClass(ti, ci, ball, sports)) >
+				scorer(ContingencyForTermAndClass(ti, ci, party, sports)),
+			"scorer %s", name,
+		)
+	}
+
+	_, err := ParseScorer("nonsense")
+	assert.NotNil(err)
+}
+
+func TestSelectPicksFromEveryClass(t *testing.T) {
+	assert := assert.New(t)
+
+	ti := testIndex()
+
+	for _, name := range ScorerNames() {
+		scorer, _ := ParseScorer(name)
+		features := Select(ti, scorer, 1, 2)
+
+		assert.True(len(features) >= 1, "scorer %s", name)
+		assert.True(len(features) <= int(ti.ClassNames.Size), "scorer %s", name)
+		assert.IsIncreasing(features, "scorer %s", name)
+	}
+
+	features := Select(ti, ChiSquaredScore, 1, 2)
+	assert.Contains(features, ti.Dictionary.Get([]byte("ball")))
+	assert.Contains(features, ti.Dictionary.Get([]byte("oil")))
+}
+
+func TestMinDocumentFrequency(t *testing.T) {
+	assert := assert.New(t)
+
+	ti := testIndex()
+	scorer := WithMinDocumentFrequency(ChiSquaredScore, 2)
+
+	// "goal", "party" and "oil" are in a single document each
+	rare := []int32{
+		ti.Dictionary.Get([]byte("goal")),
+		ti.Dictionary.Get([]byte("party")),
+		ti.Dictionary.Get([]byte("oil")),
+	}
+
+	for _, features := range [][]int32{
+		Select(ti, scorer, 10, 2),
+		SelectGlobal(ti, scorer, Max, 10, 2),
+		SelectGlobal(ti, scorer, WeightedAverage, 10, 2),
+	} {
+		assert.Equal(3, len(features))
+		for _, termID := range rare {
+			assert.NotContains(features, termID)
+		}
+	}
+}
+
+func TestScoreTableMatchesContingency(t *testing.T) {
+	assert := assert.New(t)
+
+	ti := testIndex()
+	ci := ComputeClassInfo(ti)
+
+	for _, name := range ScorerNames() {
+		scorer, _ := ParseScorer(name)
+		table := ScoreTable(ti, ci, scorer, 2)
+
+		for classID := range table {
+			for termID := range table[classID] {
+				assert.Equal(int32(termID), table[classID][termID].TermID)
+				assert.Equal(
+					scorer(ContingencyForTermAndClass(ti, ci, int32(termID), int32(classID))),
+					table[classID][termID].Score,
+					"scorer %s", name,
+				)
+			}
+		}
+	}
+}
+
+func TestSelectGlobal(t *testing.T) {
+	assert := assert.New(t)
+
+	ti := testIndex()
+	ci := ComputeClassInfo(ti)
+	table := ScoreTable(ti, ci, ChiSquaredScore, 2)
+
+	for _, aggregation := range []Aggregation{Max, WeightedAverage} {
+		scores := GlobalScores(table, ci, aggregation)
+		assert.Equal(len(table[0]), len(scores))
+
+		features := SelectGlobal(ti, ChiSquaredScore, aggregation, 3, 2)
+		assert.Equal(3, len(features), "aggregation %s", aggregation)
+		assert.IsIncreasing(features)
+
+		// "the" is in every document, so it can't be a good feature
+		assert.NotContains(features, ti.Dictionary.Get([]byte("the")), "aggregation %s", aggregation)
+	}
+
+	// terms missing from some classes must still be ranked by their average
+	mi := GlobalScores(ScoreTable(ti, ci, MutualInformationScore, 2), ci, WeightedAverage)
+	for _, score := range mi {
+		assert.False(math.IsInf(score.Score, 0))
+	}
+	assert.True(
+		mi[ti.Dictionary.Get([]byte("ball"))].Score > mi[ti.Dictionary.Get([]byte("the"))].Score,
+	)
+
+	scores := GlobalScores(table, ci, Max)
+	ball := ti.Dictionary.Get([]byte("ball"))
+	for classID := range table {
+		assert.True(scores[ball].Score >= table[classID][ball].Score)
+	}
+}
+
+func TestReport(t *testing.T) {
+	assert := assert.New(t)
+
+	ti := testIndex()
+	reports := Report(ti, ChiSquaredScore, 2, 2)
+
+	assert.Equal(int(ti.ClassNames.Size), len(reports))
+	for _, report := range reports {
+		assert.Equal(report.Class, string(ti.ClassNames.GetInverse(report.ClassID)))
+		assert.Equal(2, len(report.Terms))
+		assert.True(report.Terms[0].Score >= report.Terms[1].Score)
+
+		if report.Class == "sports" {
+			assert.Equal(int32(3), report.Documents)
+			assert.Equal("ball", report.Terms[0].Term)
+			assert.Equal(int32(3), report.Terms[0].DocumentFrequency)
+			assert.Equal(int32(3), report.Terms[0].ClassDocumentFrequency)
+		}
+	}
+
+	for _, report := range Report(ti, ChiSquaredScore, -1, 2) {
+		assert.Empty(report.Terms)
+	}
+}
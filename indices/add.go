@@ -11,6 +11,8 @@ import (
 type InfoAndTerms struct {
 	Name           string
 	Classes        []string
+	Labels         map[string][]string // label sets by name, see TotalIndex.LabelSets
+	Language       string
 	Length         int32
 	TermsAndCounts trie.Trie
 }
@@ -86,10 +88,10 @@ func (t *TotalIndex) Add(d *InfoAndTerms) {
 	//d
 	//sortedTermsAndCount
 
-	documentIndex := int32(len(t.Documents))
 	info := DocumentInfo{
-		Name:   d.Name,
-		Length: d.Length,
+		Name:     d.Name,
+		Length:   d.Length,
+		Language: d.Language,
 	}
 
 	info.Classes = make([]int32, len(d.Classes))
@@ -97,6 +99,24 @@ func (t *TotalIndex) Add(d *InfoAndTerms) {
 		info.Classes[i] = t.ClassNames.Get([]byte(d.Classes[i]))
 	}
 
+	if len(d.Labels) > 0 {
+		info.Labels = make(map[string][]int32, len(d.Labels))
+		for labelSet, labels := range d.Labels {
+			names := t.labelSet(labelSet)
+			info.Labels[labelSet] = make([]int32, len(labels))
+			for i := range labels {
+				info.Labels[labelSet][i] = names.Get([]byte(labels[i]))
+			}
+		}
+	}
+
+	t.addDocument(info, sortedTermsAndCounts)
+}
+
+// addDocument appends a document with already resolved class IDs and
+// terms sorted by ID to the forward and inverse indices
+func (t *TotalIndex) addDocument(info DocumentInfo, sortedTermsAndCounts []TermAndCount) {
+	documentIndex := int32(len(t.Documents))
 	t.Documents = append(t.Documents, info)
 
 	// d0
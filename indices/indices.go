@@ -3,6 +3,8 @@ package indices
 import (
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 
 	"github.com/DexterLB/search/serialisation"
 	"github.com/DexterLB/search/trie"
@@ -31,18 +33,36 @@ type TotalIndex struct {
 	Documents  []DocumentInfo
 	Dictionary *trie.BiDictionary // bidictionary is better for debugging
 	ClassNames *trie.BiDictionary
+
+	// LabelSets holds a dictionary for each set of labels (e.g. topics or
+	// places). ClassNames and DocumentInfo.Classes are the ones used as
+	// classification target, and can be switched with SelectLabelSet.
+	LabelSets map[string]*trie.BiDictionary
+
+	// Analyser is the JSON config of the analyser which made the terms of
+	// the documents (see package analysis), or nil if they were made by
+	// processing.EnglishTokeniser. It's kept encoded, so that the index
+	// doesn't depend on the analysis package.
+	Analyser []byte
+
+	// ShingleSize is the largest number of words in a term, if the terms
+	// include word n-grams (see processing.ShingleTokeniser)
+	ShingleSize int32
 }
 
 type DocumentInfo struct {
-	Name    string
-	Classes []int32
-	Length  int32
+	Name     string
+	Classes  []int32
+	Labels   map[string][]int32
+	Length   int32
+	Language string
 }
 
 func NewTotalIndex() *TotalIndex {
 	return &TotalIndex{
 		Dictionary: trie.NewBiDictionary(),
 		ClassNames: trie.NewBiDictionary(),
+		LabelSets:  make(map[string]*trie.BiDictionary),
 	}
 }
 
@@ -51,11 +71,68 @@ func NewOffsetTotalIndex(other *TotalIndex) *TotalIndex {
 	ni := NewTotalIndex()
 	ni.Dictionary = other.Dictionary
 	ni.ClassNames = other.ClassNames
+	ni.LabelSets = other.labelSets()
+	ni.Analyser = other.Analyser
+	ni.ShingleSize = other.ShingleSize
 	ni.ExtendInverse(len(other.Inverse.PostingLists))
 
 	return ni
 }
 
+// SelectLabelSet makes the label set with the given name the classes of
+// the index
+func (t *TotalIndex) SelectLabelSet(name string) error {
+	names, ok := t.LabelSets[name]
+	if !ok {
+		return fmt.Errorf("the index has no label set %s (available: %s)", name, strings.Join(t.LabelSetNames(), ", "))
+	}
+
+	t.ClassNames = names
+	for i := range t.Documents {
+		t.Documents[i].Classes = t.Documents[i].Labels[name]
+	}
+
+	return nil
+}
+
+// LabelSetNames returns the names of the label sets of the index, sorted
+func (t *TotalIndex) LabelSetNames() []string {
+	var names []string
+	for name := range t.LabelSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close closes all dictionaries of the index, so that unknown words and
+// labels are no longer added to them
+func (t *TotalIndex) Close() {
+	t.Dictionary.Closed = true
+	t.ClassNames.Closed = true
+	for _, names := range t.LabelSets {
+		names.Closed = true
+	}
+}
+
+// labelSets returns the label set map, creating it if needed, so that
+// it can be shared with offset indices
+func (t *TotalIndex) labelSets() map[string]*trie.BiDictionary {
+	if t.LabelSets == nil {
+		t.LabelSets = make(map[string]*trie.BiDictionary)
+	}
+	return t.LabelSets
+}
+
+// labelSet returns the dictionary of the label set, creating it if needed
+func (t *TotalIndex) labelSet(name string) *trie.BiDictionary {
+	labelSets := t.labelSets()
+	if _, ok := labelSets[name]; !ok {
+		labelSets[name] = trie.NewBiDictionary()
+	}
+	return labelSets[name]
+}
+
 func (t *TotalIndex) ExtendInverse(newLength int) {
 	for i := len(t.Inverse.PostingLists); i < newLength; i++ {
 		t.Inverse.PostingLists = append(t.Inverse.PostingLists, PostingList{
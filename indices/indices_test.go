@@ -0,0 +1,40 @@
+package indices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelSets(t *testing.T) {
+	assert := assert.New(t)
+
+	ti := NewTotalIndex()
+	for i, place := range []string{"usa", "uk", "usa"} {
+		doc := NewInfoAndTerms()
+		doc.TermsAndCounts.Put([]byte("foo"), int32(i+1))
+		doc.Length = int32(i + 1)
+		doc.Classes = []string{"earn"}
+		doc.Labels = map[string][]string{
+			"topics": {"earn"},
+			"places": {place},
+		}
+		ti.Add(doc)
+	}
+
+	test := NewOffsetTotalIndex(ti)
+	doc := NewInfoAndTerms()
+	doc.TermsAndCounts.Put([]byte("foo"), 1)
+	doc.Length = 1
+	doc.Labels = map[string][]string{"places": {"uk"}}
+	test.Add(doc)
+
+	assert.Equal([]string{"places", "topics"}, ti.LabelSetNames())
+	assert.NotNil(ti.SelectLabelSet("nonsense"))
+
+	assert.Nil(ti.SelectLabelSet("places"))
+	assert.Nil(test.SelectLabelSet("places"))
+	assert.Equal(ti.ClassNames, test.ClassNames)
+	assert.Equal([]string{"uk"}, ti.StringifyClasses(ti.Documents[1].Classes))
+	assert.Equal(ti.Documents[1].Classes, test.Documents[0].Classes)
+}
@@ -0,0 +1,64 @@
+package indices
+
+import (
+	"github.com/DexterLB/search/trie"
+)
+
+// PruneTerms rebuilds the indices with only the terms for which keep
+// returns true, given how many documents of the first index contain them
+// (so that e.g. rare phrases can be dropped based on the training set).
+// The indices must share the dictionary of the first one, like those made
+// with NewOffsetTotalIndex, and the rebuilt ones share a new dictionary.
+// Document lengths are kept, since they count words rather than terms, and
+// don't include shingles (see processing.ShingleTokeniser).
+func PruneTerms(keep func(term []byte, documentFrequency int) bool, all ...*TotalIndex) []*TotalIndex {
+	first := all[0]
+	dictionary := trie.NewBiDictionary()
+
+	// new IDs are given in the order of the old ones, so postings stay
+	// sorted by term
+	newIDs := make([]int32, first.Dictionary.Size)
+	for termID := range newIDs {
+		term := first.Dictionary.GetInverse(int32(termID))
+
+		// terms only in the other indices may be missing from the first
+		documentFrequency := 0
+		if termID < len(first.Inverse.PostingLists) {
+			first.LoopOverTermPostings(termID, func(posting *Posting) {
+				documentFrequency += 1
+			})
+		}
+
+		if keep(term, documentFrequency) {
+			newIDs[termID] = dictionary.Get(term)
+		} else {
+			newIDs[termID] = -1
+		}
+	}
+
+	pruned := make([]*TotalIndex, len(all))
+	for i, index := range all {
+		ni := NewOffsetTotalIndex(index)
+		ni.Dictionary = dictionary
+		ni.Inverse.PostingLists = nil
+		ni.ExtendInverse(int(dictionary.Size))
+
+		for docID, info := range index.Documents {
+			var termsAndCounts []TermAndCount
+			index.LoopOverDocumentPostings(docID, func(posting *Posting) {
+				if newID := newIDs[posting.Index]; newID != -1 {
+					termsAndCounts = append(termsAndCounts, TermAndCount{
+						TermID: newID,
+						Count:  posting.Count,
+					})
+				}
+			})
+
+			ni.addDocument(info, termsAndCounts)
+		}
+
+		pruned[i] = ni
+	}
+
+	return pruned
+}
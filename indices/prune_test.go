@@ -0,0 +1,61 @@
+package indices
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneTerms(t *testing.T) {
+	assert := assert.New(t)
+
+	add := func(ti *TotalIndex, terms ...string) {
+		doc := NewInfoAndTerms()
+		doc.Name = terms[0]
+		for _, term := range terms {
+			doc.TermsAndCounts.Put([]byte(term), 1)
+			// only single words count towards the length
+			if !strings.Contains(term, " ") {
+				doc.Length += 1
+			}
+		}
+		ti.Add(doc)
+	}
+
+	training := NewTotalIndex()
+	add(training, "crude", "oil", "crude oil", "rare phrase")
+	add(training, "crude", "oil", "crude oil")
+
+	test := NewOffsetTotalIndex(training)
+	add(test, "crude oil", "rare phrase", "new", "new phrase")
+	training.ExtendInverse(len(test.Inverse.PostingLists))
+
+	pruned := PruneTerms(func(term []byte, documentFrequency int) bool {
+		return documentFrequency >= 2 || !strings.Contains(string(term), " ")
+	}, training, test)
+
+	assert.Equal(pruned[0].Dictionary, pruned[1].Dictionary)
+
+	var terms []string
+	for id := int32(0); id < pruned[0].Dictionary.Size; id++ {
+		terms = append(terms, string(pruned[0].Dictionary.GetInverse(id)))
+	}
+	assert.Equal([]string{"crude", "crude oil", "oil", "new"}, terms)
+
+	termsOf := func(ti *TotalIndex, docID int) []string {
+		var terms []string
+		ti.LoopOverDocumentPostings(docID, func(posting *Posting) {
+			terms = append(terms, string(ti.Dictionary.GetInverse(posting.Index)))
+		})
+		return terms
+	}
+
+	assert.Equal([]string{"crude", "crude oil", "oil"}, termsOf(pruned[0], 0))
+	assert.Equal(int32(2), pruned[0].Documents[0].Length)
+	assert.Equal([]string{"crude oil", "new"}, termsOf(pruned[1], 0))
+	assert.Equal(int32(1), pruned[1].Documents[0].Length)
+
+	pruned[0].Verify()
+	pruned[1].Verify()
+}
@@ -0,0 +1,34 @@
+package indices
+
+// Subset creates an index with only the given documents of this one, in the
+// given order. The new index shares its dictionaries with this one, like
+// NewOffsetTotalIndex.
+func (t *TotalIndex) Subset(docIDs []int32) *TotalIndex {
+	ni := NewOffsetTotalIndex(t)
+
+	for _, docID := range docIDs {
+		var termsAndCounts []TermAndCount
+		t.LoopOverDocumentPostings(int(docID), func(posting *Posting) {
+			termsAndCounts = append(termsAndCounts, TermAndCount{
+				TermID: posting.Index,
+				Count:  posting.Count,
+			})
+		})
+
+		ni.addDocument(t.Documents[docID], termsAndCounts)
+	}
+
+	return ni
+}
+
+// DocumentsInLanguage returns the IDs of the documents in the language, to
+// be used with Subset
+func (t *TotalIndex) DocumentsInLanguage(language string) []int32 {
+	var docIDs []int32
+	for i := range t.Documents {
+		if t.Documents[i].Language == language {
+			docIDs = append(docIDs, int32(i))
+		}
+	}
+	return docIDs
+}
@@ -0,0 +1,55 @@
+package indices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubset(t *testing.T) {
+	assert := assert.New(t)
+
+	ti := NewTotalIndex()
+	for _, terms := range [][]string{{"foo", "bar"}, {"bar"}, {"qux", "foo"}} {
+		doc := NewInfoAndTerms()
+		doc.Name = terms[0]
+		doc.Classes = []string{terms[len(terms)-1]}
+		for _, term := range terms {
+			doc.TermsAndCounts.Put([]byte(term), 1)
+		}
+		doc.Length = int32(len(terms))
+		ti.Add(doc)
+	}
+
+	subset := ti.Subset([]int32{2, 0})
+	assert.Equal(ti.Dictionary, subset.Dictionary)
+	assert.Equal(2, len(subset.Documents))
+	assert.Equal(ti.Documents[2], subset.Documents[0])
+	assert.Equal(ti.Documents[0], subset.Documents[1])
+
+	var docs []int32
+	subset.LoopOverTermPostings(int(ti.Dictionary.Get([]byte("foo"))), func(posting *Posting) {
+		docs = append(docs, posting.Index)
+	})
+	assert.Equal([]int32{0, 1}, docs)
+
+	docs = nil
+	subset.LoopOverTermPostings(int(ti.Dictionary.Get([]byte("bar"))), func(posting *Posting) {
+		docs = append(docs, posting.Index)
+	})
+	assert.Equal([]int32{1}, docs)
+}
+
+func TestDocumentsInLanguage(t *testing.T) {
+	ti := NewTotalIndex()
+	for _, language := range []string{"english", "bulgarian", "english", ""} {
+		doc := NewInfoAndTerms()
+		doc.Language = language
+		doc.TermsAndCounts.Put([]byte("foo"), 1)
+		doc.Length = 1
+		ti.Add(doc)
+	}
+
+	assert.Equal(t, []int32{0, 2}, ti.DocumentsInLanguage("english"))
+	assert.Equal(t, "bulgarian", ti.Subset(ti.DocumentsInLanguage("bulgarian")).Documents[0].Language)
+}
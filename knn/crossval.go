@@ -0,0 +1,39 @@
+package knn
+
+import (
+	"log"
+
+	"github.com/DexterLB/search/evaluation"
+	"github.com/DexterLB/search/indices"
+	"github.com/DexterLB/search/sampling"
+)
+
+// Trainer builds a classifier from a training set
+type Trainer func(trainingSet *indices.TotalIndex) func(*DocumentIndex) []int32
+
+// CrossValidate splits the index into folds stratified by class, and for
+// each fold trains a classifier on the rest of the folds and evaluates it
+// on that fold. It returns the evaluation of each fold.
+func CrossValidate(ti *indices.TotalIndex, trainer Trainer, folds int, seed int64) []*evaluation.Evaluation {
+	classes := make([][]int32, len(ti.Documents))
+	for docID := range ti.Documents {
+		classes[docID] = ti.Documents[docID].Classes
+	}
+
+	assignment := sampling.StratifiedFolds(classes, folds, seed)
+
+	evaluations := make([]*evaluation.Evaluation, folds)
+	for fold := range evaluations {
+		trainingSet := ti.Subset(sampling.Exclude(assignment, fold))
+		testSet := ti.Subset(sampling.Select(assignment, fold))
+
+		log.Printf(
+			"fold %d/%d: %d training and %d test documents",
+			fold+1, folds, len(trainingSet.Documents), len(testSet.Documents),
+		)
+
+		evaluations[fold] = InteractiveTest(trainer(trainingSet), testSet, false)
+	}
+
+	return evaluations
+}
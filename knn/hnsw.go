@@ -0,0 +1,266 @@
+package knn
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// HNSWParams control the recall/latency tradeoff of the HNSW graph
+type HNSWParams struct {
+	M              int   // neighbours per node on the upper layers (2*M on layer 0)
+	EfConstruction int   // candidate list size while building
+	EfSearch       int   // candidate list size while querying
+	Seed           int64 // seed for choosing node levels
+}
+
+func DefaultHNSWParams() HNSWParams {
+	return HNSWParams{
+		M:              16,
+		EfConstruction: 200,
+		EfSearch:       50,
+		Seed:           1,
+	}
+}
+
+// Validate checks that a graph can be built with the params
+func (p HNSWParams) Validate() error {
+	if p.M < 2 {
+		return fmt.Errorf("HNSW graph needs at least 2 neighbours per node, not %d", p.M)
+	}
+	if p.EfConstruction < 1 || p.EfSearch < 1 {
+		return fmt.Errorf("HNSW candidate list sizes must be positive")
+	}
+	return nil
+}
+
+// HNSW is a hierarchical navigable small world graph over the training
+// vectors, used for approximate nearest neighbour search
+type HNSW struct {
+	Params HNSWParams
+
+	// Neighbours[node][level] are the neighbours of node on that level
+	Neighbours [][][]int32
+	EntryPoint int32
+	MaxLevel   int
+}
+
+type candidate struct {
+	node     int32
+	distance float64
+}
+
+// candidateHeap is a min-heap of candidates by distance, or a max-heap if
+// farthest is set
+type candidateHeap struct {
+	items    []candidate
+	farthest bool
+}
+
+func (h *candidateHeap) Len() int { return len(h.items) }
+func (h *candidateHeap) Less(i, j int) bool {
+	if h.farthest {
+		return h.items[i].distance > h.items[j].distance
+	}
+	return h.items[i].distance < h.items[j].distance
+}
+func (h *candidateHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *candidateHeap) Push(x interface{}) { h.items = append(h.items, x.(candidate)) }
+func (h *candidateHeap) Pop() interface{} {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// BuildGraph builds an HNSW graph over the training vectors and stores it
+// in the KNNInfo, so that ClassifyApproximate can be used. The params must
+// be valid.
+func (k *KNNInfo) BuildGraph(params HNSWParams) {
+	numNodes := len(k.Vectors)
+	g := &HNSW{
+		Params:     params,
+		Neighbours: make([][][]int32, numNodes),
+		EntryPoint: -1,
+	}
+
+	random := rand.New(rand.NewSource(params.Seed))
+	levelMultiplier := 1 / math.Log(float64(params.M))
+
+	for node := int32(0); node < int32(numNodes); node++ {
+		level := int(-math.Log(1-random.Float64()) * levelMultiplier)
+		g.Neighbours[node] = make([][]int32, level+1)
+
+		if g.EntryPoint == -1 {
+			g.EntryPoint = node
+			g.MaxLevel = level
+			continue
+		}
+
+		dist := func(other int32) float64 { return k.nodeDistance(node, other) }
+
+		entry := []candidate{{node: g.EntryPoint, distance: dist(g.EntryPoint)}}
+		for l := g.MaxLevel; l > level; l-- {
+			entry = g.searchLayer(dist, entry, 1, l)
+		}
+
+		for l := minInt(level, g.MaxLevel); l >= 0; l-- {
+			entry = g.searchLayer(dist, entry, params.EfConstruction, l)
+
+			neighbours := g.selectNeighbours(k, entry, params.M)
+			for _, neighbour := range neighbours {
+				g.Neighbours[node][l] = append(g.Neighbours[node][l], neighbour.node)
+				g.connect(k, neighbour.node, node, l)
+			}
+		}
+
+		if level > g.MaxLevel {
+			g.MaxLevel = level
+			g.EntryPoint = node
+		}
+	}
+
+	k.Graph = g
+}
+
+// connect adds to as a neighbour of from on the given level, dropping the
+// farthest neighbour if from has too many
+func (g *HNSW) connect(k *KNNInfo, from int32, to int32, level int) {
+	neighbours := append(g.Neighbours[from][level], to)
+
+	maxNeighbours := g.Params.M
+	if level == 0 {
+		maxNeighbours *= 2
+	}
+
+	if len(neighbours) > maxNeighbours {
+		candidates := make([]candidate, len(neighbours))
+		for i, neighbour := range neighbours {
+			candidates[i] = candidate{node: neighbour, distance: k.nodeDistance(from, neighbour)}
+		}
+		candidates = g.selectNeighbours(k, candidates, maxNeighbours)
+
+		neighbours = neighbours[:0]
+		for _, c := range candidates {
+			neighbours = append(neighbours, c.node)
+		}
+	}
+
+	g.Neighbours[from][level] = neighbours
+}
+
+// selectNeighbours picks up to n of the candidates (whose distances are to
+// some base node) as neighbours. A candidate is preferred if it's closer to
+// the base than to any neighbour picked so far, which keeps links between
+// clusters instead of linking only within the closest one.
+func (g *HNSW) selectNeighbours(k *KNNInfo, candidates []candidate, n int) []candidate {
+	var selected, discarded []candidate
+
+	for _, c := range closest(candidates, len(candidates)) {
+		if len(selected) >= n {
+			break
+		}
+
+		diverse := true
+		for _, s := range selected {
+			if k.nodeDistance(c.node, s.node) < c.distance {
+				diverse = false
+				break
+			}
+		}
+
+		if diverse {
+			selected = append(selected, c)
+		} else {
+			discarded = append(discarded, c)
+		}
+	}
+
+	for i := 0; i < len(discarded) && len(selected) < n; i++ {
+		selected = append(selected, discarded[i])
+	}
+
+	return selected
+}
+
+// searchLayer performs a greedy beam search on one level of the graph,
+// returning up to ef candidates closest to the query
+func (g *HNSW) searchLayer(dist func(int32) float64, entry []candidate, ef int, level int) []candidate {
+	visited := make(map[int32]struct{}, ef*4)
+	toVisit := &candidateHeap{}
+	found := &candidateHeap{farthest: true}
+
+	for _, c := range entry {
+		visited[c.node] = struct{}{}
+		heap.Push(toVisit, c)
+		heap.Push(found, c)
+	}
+
+	for toVisit.Len() > 0 {
+		current := heap.Pop(toVisit).(candidate)
+		if found.Len() >= ef && current.distance > found.items[0].distance {
+			break
+		}
+
+		for _, neighbour := range g.Neighbours[current.node][level] {
+			if _, ok := visited[neighbour]; ok {
+				continue
+			}
+			visited[neighbour] = struct{}{}
+
+			d := dist(neighbour)
+			if found.Len() < ef || d < found.items[0].distance {
+				heap.Push(toVisit, candidate{node: neighbour, distance: d})
+				heap.Push(found, candidate{node: neighbour, distance: d})
+				if found.Len() > ef {
+					heap.Pop(found)
+				}
+			}
+		}
+	}
+
+	return found.items
+}
+
+// search finds approximately the bestK closest nodes to the query
+func (g *HNSW) search(dist func(int32) float64, bestK int) []candidate {
+	if g.EntryPoint == -1 {
+		return nil
+	}
+
+	entry := []candidate{{node: g.EntryPoint, distance: dist(g.EntryPoint)}}
+	for l := g.MaxLevel; l > 0; l-- {
+		entry = g.searchLayer(dist, entry, 1, l)
+	}
+
+	ef := g.Params.EfSearch
+	if ef < bestK {
+		ef = bestK
+	}
+
+	return closest(g.searchLayer(dist, entry, ef, 0), bestK)
+}
+
+// nodeDistance is the distance between two training documents
+func (k *KNNInfo) nodeDistance(a int32, b int32) float64 {
+	dot, minSum := shared(&k.Vectors[a], &k.Vectors[b])
+	return k.Metric.distance(&k.Vectors[a], &k.Vectors[b], dot, minSum)
+}
+
+// closest returns (a sorted copy of) the n candidates with smallest distance
+func closest(candidates []candidate, n int) []candidate {
+	sorted := append([]candidate(nil), candidates...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].distance < sorted[j].distance })
+	if n < len(sorted) {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,125 @@
+package knn
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/DexterLB/search/indices"
+	"github.com/DexterLB/search/lsh"
+	"github.com/DexterLB/search/serialisation"
+	"github.com/stretchr/testify/assert"
+)
+
+func randomIndex(numDocuments int, seed int64) *indices.TotalIndex {
+	return addRandomDocuments(indices.NewTotalIndex(), numDocuments, seed)
+}
+
+// addRandomDocuments adds documents to the index, which may share the
+// dictionary of a training index, and returns it
+func addRandomDocuments(ti *indices.TotalIndex, numDocuments int, seed int64) *indices.TotalIndex {
+	random := rand.New(rand.NewSource(seed))
+
+	for i := 0; i < numDocuments; i++ {
+		doc := indices.NewInfoAndTerms()
+		doc.Name = fmt.Sprintf("doc%d", i)
+		class := random.Intn(5)
+		doc.Classes = []string{fmt.Sprintf("class%d", class)}
+
+		for j := 0; j < 20; j++ {
+			// documents of the same class prefer the same terms
+			term := fmt.Sprintf("term%d", class*10+random.Intn(30))
+			doc.TermsAndCounts.PutLambda([]byte(term), func(x int32) int32 { return x + 1 }, 1)
+			doc.Length += 1
+		}
+
+		ti.Add(doc)
+	}
+
+	return ti
+}
+
+func TestApproximateNeighbours(t *testing.T) {
+	assert := assert.New(t)
+
+	training := randomIndex(500, 1)
+	test := addRandomDocuments(indices.NewOffsetTotalIndex(training), 50, 2)
+
+	params := DefaultHNSWParams()
+	ki := Preprocess(training, &PreprocessOptions{TermsPerClass: 10, Metric: Cosine, Graph: &params}, 2)
+
+	recall := &NeighbourRecall{}
+	for docID := range test.Documents {
+		recall.Measure(ki, &DocumentIndex{
+			Postings:    test.Forward.Postings,
+			PostingList: &test.Forward.PostingLists[docID],
+			Length:      test.Documents[docID].Length,
+		}, 5, 2)
+	}
+
+	assert.True(recall.Recall() > 0.9, "recall is %s", recall)
+}
+
+func TestHNSWParams(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(DefaultHNSWParams().Validate())
+
+	for _, change := range []func(p *HNSWParams){
+		func(p *HNSWParams) { p.M = 1 },
+		func(p *HNSWParams) { p.M = 0 },
+		func(p *HNSWParams) { p.EfConstruction = 0 },
+		func(p *HNSWParams) { p.EfSearch = -1 },
+	} {
+		params := DefaultHNSWParams()
+		change(&params)
+		assert.NotNil(params.Validate(), "%+v", params)
+	}
+}
+
+func TestGraphSerialisation(t *testing.T) {
+	assert := assert.New(t)
+
+	params := DefaultHNSWParams()
+	ki := Preprocess(randomIndex(100, 1), &PreprocessOptions{TermsPerClass: 10, Metric: Euclidean, Graph: &params}, 2)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(serialisation.SerialiseTo(ki, buf))
+
+	loaded := &KNNInfo{}
+	assert.Nil(serialisation.DeserialiseFrom(loaded, buf))
+
+	assert.Equal(ki.Graph, loaded.Graph)
+	assert.Equal(ki.Metric, loaded.Metric)
+}
+
+func TestHashedNeighbours(t *testing.T) {
+	assert := assert.New(t)
+
+	training := randomIndex(500, 1)
+	test := addRandomDocuments(indices.NewOffsetTotalIndex(training), 50, 2)
+
+	params := lsh.DefaultParams()
+	params.Rows = 4
+	ki := Preprocess(training, &PreprocessOptions{TermsPerClass: 10, Metric: Cosine, Hashing: &params}, 2)
+
+	recall := &NeighbourRecall{}
+	for docID := range test.Documents {
+		recall.Measure(ki, &DocumentIndex{
+			Postings:    test.Forward.Postings,
+			PostingList: &test.Forward.PostingLists[docID],
+			Length:      test.Documents[docID].Length,
+		}, 5, 2)
+	}
+
+	assert.True(recall.Recall() > 0.8, "recall is %s", recall)
+}
+
+func TestRecallWithoutNeighbours(t *testing.T) {
+	assert := assert.New(t)
+
+	recall := &NeighbourRecall{Queries: 3}
+	assert.Equal(1.0, recall.Recall())
+	assert.Contains(recall.String(), "recall: 1.000")
+}
@@ -3,9 +3,11 @@ package knn
 import (
 	"math"
 	"sort"
+	"sync"
 
 	"github.com/DexterLB/search/featureselection"
 	"github.com/DexterLB/search/indices"
+	"github.com/DexterLB/search/lsh"
 	"github.com/DexterLB/search/utils"
 )
 
@@ -16,18 +18,72 @@ type DocumentIndex struct {
 }
 
 type KNNInfo struct {
-	FeatureIDFs []float64
-	Features    []int32
-	Index       *indices.TotalIndex
+	FeatureIDFs   []float64
+	Features      []int32
+	Index         *indices.TotalIndex
+	Metric        Metric
+	Weighting     Weighting
+	AverageLength float64
+	Vectors       []SparseVector
+	Graph         *HNSW      // nil unless an approximate search graph was built
+	Hashes        *lsh.Index // nil unless an LSH index was built
+
+	columns     []SparseVector
+	columnsOnce sync.Once
 }
 
-func Preprocess(ti *indices.TotalIndex, termsPerClass int32, parallelWorkers int) *KNNInfo {
-	features := featureselection.ChiSquared(ti, termsPerClass, parallelWorkers)
-	return &KNNInfo{
-		Features:    features,
-		FeatureIDFs: computeIDFs(features, ti),
-		Index:       ti,
+// PreprocessOptions configure feature selection, weighting and the optional
+// approximate search structures built by Preprocess
+type PreprocessOptions struct {
+	Scorer        featureselection.Scorer // chi-squared if nil
+	Aggregation   featureselection.Aggregation
+	TermsPerClass int32 // used with round robin aggregation
+	TotalTerms    int32 // used with global aggregations
+	MinDocuments  int   // terms in fewer training documents are never selected
+	Metric        Metric
+	Weighting     Weighting
+	Graph         *HNSWParams // build an HNSW graph if not nil
+	Hashing       *lsh.Params // build an LSH index if not nil
+}
+
+func Preprocess(ti *indices.TotalIndex, options *PreprocessOptions, parallelWorkers int) *KNNInfo {
+	scorer := options.Scorer
+	if scorer == nil {
+		scorer = featureselection.ChiSquaredScore
+	}
+	if options.MinDocuments > 1 {
+		scorer = featureselection.WithMinDocumentFrequency(scorer, options.MinDocuments)
+	}
+
+	var features []int32
+	if options.Aggregation == featureselection.RoundRobin {
+		features = featureselection.Select(ti, scorer, options.TermsPerClass, parallelWorkers)
+	} else {
+		features = featureselection.SelectGlobal(ti, scorer, options.Aggregation, options.TotalTerms, parallelWorkers)
+	}
+
+	return FromFeatures(ti, features, options)
+}
+
+// FromFeatures builds the classifier data for already selected features,
+// ignoring the feature selection options
+func FromFeatures(ti *indices.TotalIndex, features []int32, options *PreprocessOptions) *KNNInfo {
+	ki := &KNNInfo{
+		Features:      features,
+		FeatureIDFs:   computeIDFs(features, ti),
+		Index:         ti,
+		Metric:        options.Metric,
+		Weighting:     options.Weighting,
+		AverageLength: averageLength(ti),
+	}
+	ki.ComputeVectors()
+	if options.Graph != nil {
+		ki.BuildGraph(*options.Graph)
+	}
+	if options.Hashing != nil {
+		ki.BuildHashes(*options.Hashing)
 	}
+	return ki
 }
 
 type DocumentDistance struct {
@@ -36,24 +92,80 @@ type DocumentDistance struct {
 }
 
 func (k *KNNInfo) ClassifyForward(document *DocumentIndex, bestK int, parallelWorkers int) []int32 {
+	return k.bestClasses(k.ExactNeighbours(document, bestK, parallelWorkers))
+}
+
+func (k *KNNInfo) ClassifyInverse(document *DocumentIndex, bestK int) []int32 {
 	distances := make(chan *DocumentDistance, 200)
 	go func() {
-		k.forwardDistances(document, parallelWorkers, distances)
+		k.distanceToAll(document, distances)
 		close(distances)
 	}()
-	return k.bestClasses(distances, bestK)
+	return k.bestClasses(nearest(distances, bestK))
 }
 
-func (k *KNNInfo) ClassifyInverse(document *DocumentIndex, bestK int) []int32 {
+// ClassifyApproximate classifies using the HNSW graph or the LSH index
+// instead of scanning all training documents
+func (k *KNNInfo) ClassifyApproximate(document *DocumentIndex, bestK int) []int32 {
+	return k.bestClasses(k.ApproximateNeighbours(document, bestK))
+}
+
+// HasApproximateSearch tells if an HNSW graph or an LSH index was built
+func (k *KNNInfo) HasApproximateSearch() bool {
+	return k.Graph != nil || k.Hashes != nil
+}
+
+// ExactNeighbours finds the bestK closest training documents by comparing
+// against all of them
+func (k *KNNInfo) ExactNeighbours(document *DocumentIndex, bestK int, parallelWorkers int) []*DocumentDistance {
 	distances := make(chan *DocumentDistance, 200)
 	go func() {
-		k.distanceToAll(document, distances)
+		k.forwardDistances(document, parallelWorkers, distances)
 		close(distances)
 	}()
-	return k.bestClasses(distances, bestK)
+	return nearest(distances, bestK)
+}
+
+// ApproximateNeighbours finds approximately the bestK closest training
+// documents using the HNSW graph, or if there is none, by comparing only
+// against the candidates from the LSH index
+func (k *KNNInfo) ApproximateNeighbours(document *DocumentIndex, bestK int) []*DocumentDistance {
+	query := k.queryVector(document)
+
+	if k.Graph != nil {
+		found := k.Graph.search(func(node int32) float64 {
+			return k.queryDistance(query, node)
+		}, bestK)
+
+		neighbours := make([]*DocumentDistance, len(found))
+		for i := range found {
+			neighbours[i] = &DocumentDistance{DocumentID: found[i].node, Distance: found[i].distance}
+		}
+		return neighbours
+	}
+
+	if k.Hashes != nil {
+		candidates := k.Hashes.Candidates(query.Indices, query.Weights)
+
+		distances := make(chan *DocumentDistance, len(candidates))
+		for _, docID := range candidates {
+			distances <- &DocumentDistance{DocumentID: docID, Distance: k.queryDistance(query, docID)}
+		}
+		close(distances)
+
+		return nearest(distances, bestK)
+	}
+
+	panic("no approximate search structure was built for this KNN info")
 }
 
-func (k *KNNInfo) bestClasses(distances <-chan *DocumentDistance, bestK int) []int32 {
+// queryDistance is the distance between a query and a training document
+func (k *KNNInfo) queryDistance(query *SparseVector, docID int32) float64 {
+	dot, minSum := shared(query, &k.Vectors[docID])
+	return k.Metric.distance(query, &k.Vectors[docID], dot, minSum)
+}
+
+func nearest(distances <-chan *DocumentDistance, bestK int) []*DocumentDistance {
 	var allDistances []*DocumentDistance
 	for dist := range distances {
 		allDistances = append(allDistances, dist)
@@ -69,7 +181,10 @@ func (k *KNNInfo) bestClasses(distances <-chan *DocumentDistance, bestK int) []i
 		bestK = len(allDistances)
 	}
 
-	bestDistances := allDistances[0:bestK]
+	return allDistances[0:bestK]
+}
+
+func (k *KNNInfo) bestClasses(bestDistances []*DocumentDistance) []int32 {
 	// bestDocs := make([]string, len(bestDistances))
 	// for i := range bestDistances {
 	// 	bestDocs[i] = fmt.Sprintf("%d(%.2f)", bestDistances[i].DocumentID, bestDistances[i].Distance)
@@ -109,9 +224,10 @@ func (k *KNNInfo) bestClasses(distances <-chan *DocumentDistance, bestK int) []i
 
 func (k *KNNInfo) forwardDistances(document *DocumentIndex, parallelWorkers int, distances chan<- *DocumentDistance) {
 	docsToProcess := make(chan int32, 200)
+	query := k.queryVector(document)
 
 	go func() {
-		for docID := range k.Index.Forward.PostingLists {
+		for docID := range k.Vectors {
 			docsToProcess <- int32(docID)
 		}
 
@@ -122,11 +238,7 @@ func (k *KNNInfo) forwardDistances(document *DocumentIndex, parallelWorkers int,
 		func() {
 			for docID := range docsToProcess {
 				distances <- &DocumentDistance{
-					Distance: k.distance(document, &DocumentIndex{
-						Postings:    k.Index.Forward.Postings,
-						PostingList: &k.Index.Forward.PostingLists[docID],
-						Length:      k.Index.Documents[docID].Length,
-					}),
+					Distance:   k.queryDistance(query, docID),
 					DocumentID: docID,
 				}
 			}
@@ -135,100 +247,50 @@ func (k *KNNInfo) forwardDistances(document *DocumentIndex, parallelWorkers int,
 	)
 }
 
-func (k *KNNInfo) distance(a *DocumentIndex, b *DocumentIndex) float64 {
-	postingAindex := a.PostingList.FirstIndex
-	postingBindex := b.PostingList.FirstIndex
-
-	dist := float64(0)
-
-	for featureIndex, featureID := range k.Features {
-		for postingAindex >= 0 && a.Postings[postingAindex].Index < featureID {
-			postingAindex = a.Postings[postingAindex].NextPostingIndex
-		}
-		for postingBindex >= 0 && b.Postings[postingBindex].Index < featureID {
-			postingBindex = b.Postings[postingBindex].NextPostingIndex
-		}
-
-		if postingAindex == -1 && postingBindex == -1 {
-			break
-		}
-
-		termA := float64(0)
-		termB := float64(0)
-
-		if postingAindex >= 0 {
-			postingA := &a.Postings[postingAindex]
-			if postingA.Index == featureID {
-				termA = k.value(featureIndex, postingA.Count, a)
-			}
-		}
-
-		if postingBindex >= 0 {
-			postingB := &b.Postings[postingBindex]
-			if postingB.Index == featureID {
-				termB = k.value(featureIndex, postingB.Count, b)
-			}
-		}
-
-		dist += square(termA - termB)
+// value is the weight of a feature in the document being classified
+func (k *KNNInfo) value(featureIndex int, count int32, document *DocumentIndex) float64 {
+	switch k.Metric {
+	case Jaccard:
+		return 1
+	case BM25:
+		return float64(count)
 	}
 
-	return dist
+	return k.Weighting.weight(count, document.Length, k.FeatureIDFs[featureIndex])
 }
 
-func (k *KNNInfo) value(featureIndex int, count int32, document *DocumentIndex) float64 {
-	tf := float64(count) / float64(document.Length)
-	idf := k.FeatureIDFs[featureIndex]
-	return tf * idf
+// trainingValue is the weight of a feature in a training document
+func (k *KNNInfo) trainingValue(featureIndex int, count int32, document *DocumentIndex) float64 {
+	if k.Metric != BM25 {
+		return k.value(featureIndex, count, document)
+	}
+
+	tf := float64(count)
+	norm := 1 - bm25B + bm25B*float64(document.Length)/k.AverageLength
+	return k.FeatureIDFs[featureIndex] * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
 }
 
 func (k *KNNInfo) distanceToAll(document *DocumentIndex, distances chan<- *DocumentDistance) {
-	postingLists := k.Index.Inverse.PostingLists
-	postings := k.Index.Inverse.Postings
-
-	docVec := k.documentVector(document)
-
-	numFeatures := len(k.Features)
-
-	currentPostingIndices := make([]int32, numFeatures)
-	docIndex := int32(math.MaxInt32)
-	for i := 0; i < numFeatures; i += 1 {
-		currentPostingIndices[i] = postingLists[k.Features[i]].FirstIndex
-		if currentPostingIndices[i] < docIndex {
-			docIndex = currentPostingIndices[i]
+	columns := k.featureColumns()
+	query := k.queryVector(document)
+
+	dots := make([]float64, len(k.Vectors))
+	minSums := make([]float64, len(k.Vectors))
+
+	for i, featureIndex := range query.Indices {
+		queryWeight := query.Weights[i]
+		column := &columns[featureIndex]
+		for j, docID := range column.Indices {
+			dots[docID] += queryWeight * column.Weights[j]
+			minSums[docID] += math.Min(queryWeight, column.Weights[j])
 		}
 	}
 
-	for docIndex != int32(math.MaxInt32) {
-		minDocIndex := int32(math.MaxInt32)
-		distance := float64(0)
-
-		for i := 0; i < numFeatures; i += 1 {
-			if currentPostingIndices[i] == -1 {
-				distance += square(docVec[i])
-				continue
-			}
-
-			posting := &postings[currentPostingIndices[i]]
-
-			if posting.Index == docIndex {
-				distance += square(k.value(i, posting.Count, k.documentIndex(docIndex)) - docVec[i])
-				currentPostingIndices[i] = postings[currentPostingIndices[i]].NextPostingIndex
-			} else {
-				distance += square(docVec[i])
-			}
-
-			if currentPostingIndices[i] != -1 && postings[currentPostingIndices[i]].Index < minDocIndex {
-				minDocIndex = postings[currentPostingIndices[i]].Index
-			}
-		}
-
+	for docID := range k.Vectors {
 		distances <- &DocumentDistance{
-			DocumentID: docIndex,
-			Distance:   distance,
+			DocumentID: int32(docID),
+			Distance:   k.Metric.distance(query, &k.Vectors[docID], dots[docID], minSums[docID]),
 		}
-
-		docIndex = minDocIndex
 	}
 }
 
@@ -240,23 +302,6 @@ func (k *KNNInfo) documentIndex(docID int32) *DocumentIndex {
 	}
 }
 
-func (k *KNNInfo) documentVector(document *DocumentIndex) []float64 {
-	docVec := make([]float64, len(k.Features))
-
-	postingIndex := document.PostingList.FirstIndex
-	for i, featureIndex := range k.Features {
-		for postingIndex >= 0 && document.Postings[postingIndex].Index < featureIndex {
-			postingIndex = document.Postings[postingIndex].NextPostingIndex
-		}
-
-		if postingIndex >= 0 && document.Postings[postingIndex].Index == featureIndex {
-			docVec[i] = k.value(i, document.Postings[postingIndex].Count, document)
-		}
-	}
-
-	return docVec
-}
-
 func computeIDFs(featureIDs []int32, ti *indices.TotalIndex) []float64 {
 	IDFs := make([]float64, len(featureIDs))
 	for i := range IDFs {
@@ -264,12 +309,30 @@ func computeIDFs(featureIDs []int32, ti *indices.TotalIndex) []float64 {
 		ti.LoopOverTermPostings(int(featureIDs[i]), func(posting *indices.Posting) {
 			docCount += 1
 		})
+		if docCount == 0 {
+			// the term doesn't occur in this index (e.g. a subset of a
+			// larger one), so it carries no information
+			continue
+		}
 		IDFs[i] = math.Log(float64(len(ti.Forward.PostingLists)) / float64(docCount))
 	}
 
 	return IDFs
 }
 
+func averageLength(ti *indices.TotalIndex) float64 {
+	if len(ti.Documents) == 0 {
+		return 0
+	}
+
+	total := float64(0)
+	for i := range ti.Documents {
+		total += float64(ti.Documents[i].Length)
+	}
+
+	return total / float64(len(ti.Documents))
+}
+
 func square(x float64) float64 {
 	return x * x
 }
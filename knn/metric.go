@@ -0,0 +1,93 @@
+package knn
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Metric selects how the distance between two documents is computed
+type Metric int32
+
+const (
+	// Euclidean is the squared Euclidean distance between weighted vectors
+	Euclidean Metric = iota
+	// Cosine is one minus the cosine similarity of weighted vectors
+	Cosine
+	// Manhattan is the sum of absolute differences of weighted vectors
+	Manhattan
+	// Jaccard is one minus the Jaccard index of the sets of present features
+	Jaccard
+	// BM25 is the negated dot product of the query term counts and the
+	// BM25 weights of the training document
+	BM25
+)
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+var metricNames = map[string]Metric{
+	"euclidean": Euclidean,
+	"cosine":    Cosine,
+	"manhattan": Manhattan,
+	"jaccard":   Jaccard,
+	"bm25":      BM25,
+}
+
+// ParseMetric returns the metric with the given name
+func ParseMetric(name string) (Metric, error) {
+	metric, ok := metricNames[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown metric %s (available: %s)", name, strings.Join(MetricNames(), ", "))
+	}
+	return metric, nil
+}
+
+// MetricNames lists the names accepted by ParseMetric
+func MetricNames() []string {
+	var names []string
+	for name := range metricNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m Metric) String() string {
+	for name, metric := range metricNames {
+		if metric == m {
+			return name
+		}
+	}
+	return fmt.Sprintf("metric(%d)", int32(m))
+}
+
+// distance combines the precomputed sums of two vectors with the sums
+// over their shared features
+func (m Metric) distance(a *SparseVector, b *SparseVector, dot float64, minSum float64) float64 {
+	switch m {
+	case Cosine:
+		if a.SquaredNorm == 0 || b.SquaredNorm == 0 {
+			return 1
+		}
+		return 1 - dot/(math.Sqrt(a.SquaredNorm)*math.Sqrt(b.SquaredNorm))
+	case Manhattan:
+		// weights are non-negative, so |x - y| = x + y - 2 min(x, y)
+		return a.Sum + b.Sum - 2*minSum
+	case Jaccard:
+		// weights are 0 or 1, so the sums are set sizes
+		union := a.Sum + b.Sum - dot
+		if union == 0 {
+			return 1
+		}
+		return 1 - dot/union
+	case BM25:
+		return -dot
+	default:
+		return math.Max(0, a.SquaredNorm+b.SquaredNorm-2*dot)
+	}
+}
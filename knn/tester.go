@@ -6,12 +6,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/DexterLB/search/evaluation"
 	"github.com/DexterLB/search/indices"
 	"github.com/DexterLB/search/trie"
 )
 
-func InteractiveTest(classifier func(*DocumentIndex) []int32, testSet *indices.TotalIndex) {
-	total := &TestResult{}
+// InteractiveTest classifies every document of the test set and evaluates
+// the results. If logDocuments is set, the actual and predicted classes of
+// every document are logged as well.
+func InteractiveTest(classifier func(*DocumentIndex) []int32, testSet *indices.TotalIndex, logDocuments bool) *evaluation.Evaluation {
+	total := evaluation.New(testSet.ClassNames)
 	var elapsed time.Duration
 	for docID := range testSet.Documents {
 		actualClasses := testSet.Documents[docID].Classes
@@ -24,83 +28,98 @@ func InteractiveTest(classifier func(*DocumentIndex) []int32, testSet *indices.T
 		})
 		elapsed += time.Since(start)
 
-		total.Add(Compare(actualClasses, resultClasses, testSet.ClassNames))
-	}
-
-	avgElapsed := elapsed / time.Duration(len(testSet.Documents))
-
-	log.Printf("totals: %s", total)
-	log.Printf("classification took %s on average per document", avgElapsed)
-}
+		total.Add(testSet.Documents[docID].Name, actualClasses, resultClasses)
 
-type TestResult struct {
-	PrecisionSum     int
-	RecallSum        int
-	PrecisionDivisor int
-	RecallDivisor    int
-}
+		if logDocuments {
+			log.Printf("document %s", testSet.Documents[docID].Name)
+			log.Printf("actual: %s", strings.Join(stringifyClasses(actualClasses, testSet.ClassNames), ", "))
+			log.Printf("result: %s", strings.Join(stringifyClasses(resultClasses, testSet.ClassNames), ", "))
+		}
+	}
 
-func (t *TestResult) Precision() float64 {
-	return float64(t.PrecisionSum) / float64(t.PrecisionDivisor)
-}
+	log.Printf("totals: %s", total.Summary())
+	if len(testSet.Documents) > 0 {
+		avgElapsed := elapsed / time.Duration(len(testSet.Documents))
+		log.Printf("classification took %s on average per document", avgElapsed)
+	}
 
-func (t *TestResult) Recall() float64 {
-	return float64(t.RecallSum) / float64(t.RecallDivisor)
+	return total
 }
 
-func (t *TestResult) FScore() float64 {
-	if t.Precision()+t.Recall() == 0 {
-		return 0
+// MeasureRecall compares approximate and exact search for every document
+// of the test set
+func MeasureRecall(k *KNNInfo, testSet *indices.TotalIndex, bestK int, parallelWorkers int) *NeighbourRecall {
+	recall := &NeighbourRecall{}
+	for docID := range testSet.Documents {
+		recall.Measure(k, &DocumentIndex{
+			Postings:    testSet.Forward.Postings,
+			PostingList: &testSet.Forward.PostingLists[docID],
+			Length:      testSet.Documents[docID].Length,
+		}, bestK, parallelWorkers)
 	}
-	return (t.Precision() * t.Recall()) / (t.Precision() + t.Recall()) * 2
-}
-
-func (t *TestResult) Add(other *TestResult) {
-	t.PrecisionSum += other.PrecisionSum
-	t.RecallSum += other.RecallSum
-	t.PrecisionDivisor += other.PrecisionDivisor
-	t.RecallDivisor += other.RecallDivisor
+	return recall
 }
 
-func (t *TestResult) String() string {
-	return fmt.Sprintf("precision: %.2f, recall: %.2f, fscore: %.2f", t.Precision(), t.Recall(), t.FScore())
+// NeighbourRecall compares approximate nearest neighbour search against
+// exact search
+type NeighbourRecall struct {
+	Found              int // exact neighbours which were also found approximately
+	Total              int // exact neighbours
+	Queries            int
+	ExactElapsed       time.Duration
+	ApproximateElapsed time.Duration
 }
 
-func Compare(actualClasses []int32, resultClasses []int32, classDic *trie.BiDictionary) *TestResult {
-	tr := &TestResult{}
-
-	actualSet := classSet(actualClasses)
-	resultSet := classSet(resultClasses)
-
-	for _, ac := range actualClasses {
-		if _, ok := resultSet[ac]; ok {
-			tr.RecallSum += 1
+// Measure runs both exact and approximate search for the document and
+// records how many of the exact neighbours were found
+func (n *NeighbourRecall) Measure(k *KNNInfo, document *DocumentIndex, bestK int, parallelWorkers int) {
+	start := time.Now()
+	exact := k.ExactNeighbours(document, bestK, parallelWorkers)
+	n.ExactElapsed += time.Since(start)
+
+	start = time.Now()
+	approximate := k.ApproximateNeighbours(document, bestK)
+	n.ApproximateElapsed += time.Since(start)
+
+	// count by distance rather than by document, so that ties at the k-th
+	// place don't count as misses
+	found := 0
+	if len(exact) > 0 {
+		threshold := exact[len(exact)-1].Distance + 1e-9
+		for _, neighbour := range approximate {
+			if neighbour.Distance <= threshold {
+				found += 1
+			}
 		}
-		tr.RecallDivisor += 1
 	}
-
-	for _, rc := range resultClasses {
-		if _, ok := actualSet[rc]; ok {
-			tr.PrecisionSum += 1
-		}
-		tr.PrecisionDivisor += 1
+	if found > len(exact) {
+		found = len(exact)
 	}
 
-	log.Printf("actual: %s", strings.Join(stringifyClasses(actualClasses, classDic), ", "))
-	log.Printf("result: %s", strings.Join(stringifyClasses(resultClasses, classDic), ", "))
-	log.Printf("%s", tr)
-
-	return tr
+	n.Found += found
+	n.Total += len(exact)
+	n.Queries += 1
 }
 
-func classSet(classes []int32) map[int32]struct{} {
-	set := make(map[int32]struct{})
-
-	for _, class := range classes {
-		set[class] = struct{}{}
+// Recall is the fraction of the exact neighbours which were found, or 1 if
+// there were none to find
+func (n *NeighbourRecall) Recall() float64 {
+	if n.Total == 0 {
+		return 1
 	}
+	return float64(n.Found) / float64(n.Total)
+}
 
-	return set
+func (n *NeighbourRecall) String() string {
+	if n.Queries == 0 {
+		return "no queries"
+	}
+	return fmt.Sprintf(
+		"recall: %.3f, exact search: %s, approximate search: %s on average per document",
+		n.Recall(),
+		n.ExactElapsed/time.Duration(n.Queries),
+		n.ApproximateElapsed/time.Duration(n.Queries),
+	)
 }
 
 func stringifyClasses(classes []int32, dic *trie.BiDictionary) []string {
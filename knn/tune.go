@@ -0,0 +1,146 @@
+package knn
+
+import (
+	"log"
+	"sort"
+
+	"github.com/DexterLB/search/evaluation"
+	"github.com/DexterLB/search/featureselection"
+	"github.com/DexterLB/search/indices"
+	"github.com/DexterLB/search/utils"
+)
+
+// TuneGrid lists the values of each hyperparameter to try
+type TuneGrid struct {
+	Ks            []int
+	TermsPerClass []int32
+	Metrics       []Metric
+	Weightings    []Weighting
+}
+
+// TuneResult is the evaluation of one combination of hyperparameters
+type TuneResult struct {
+	K             int
+	TermsPerClass int32
+	Metric        Metric
+	Weighting     Weighting
+	Summary       *evaluation.Summary
+}
+
+// tuneJob is a combination of hyperparameters which needs its own vectors.
+// All values of k are evaluated from the same neighbours.
+type tuneJob struct {
+	index         int
+	termsPerClass int32
+	metric        Metric
+	weighting     Weighting
+}
+
+// Tune evaluates every combination of hyperparameters in the grid by
+// training on the training set and classifying the test set, and returns
+// the results from best to worst by micro F1 (and then macro F1). The term
+// scores are computed only once, and the combinations are evaluated in
+// parallel. Metrics which ignore the weighting are only evaluated with the
+// first weighting of the grid.
+func Tune(trainingSet *indices.TotalIndex, testSet *indices.TotalIndex, scorer featureselection.Scorer, grid *TuneGrid, parallelWorkers int) []*TuneResult {
+	if scorer == nil {
+		scorer = featureselection.ChiSquaredScore
+	}
+
+	info := featureselection.ComputeClassInfo(trainingSet)
+	table := featureselection.SortedScoreTable(trainingSet, info, scorer, parallelWorkers)
+
+	features := make(map[int32][]int32)
+	for _, termsPerClass := range grid.TermsPerClass {
+		features[termsPerClass] = featureselection.TopTerms(table, termsPerClass)
+	}
+
+	var jobs []tuneJob
+	for _, termsPerClass := range grid.TermsPerClass {
+		for _, metric := range grid.Metrics {
+			for i, weighting := range grid.Weightings {
+				if i > 0 && (metric == Jaccard || metric == BM25) {
+					continue
+				}
+				jobs = append(jobs, tuneJob{
+					index:         len(jobs),
+					termsPerClass: termsPerClass,
+					metric:        metric,
+					weighting:     weighting,
+				})
+			}
+		}
+	}
+
+	maxK := 0
+	for _, k := range grid.Ks {
+		if k > maxK {
+			maxK = k
+		}
+	}
+
+	results := make([]*TuneResult, len(jobs)*len(grid.Ks))
+
+	work := make(chan tuneJob, len(jobs))
+	for _, job := range jobs {
+		work <- job
+	}
+	close(work)
+
+	utils.Parallel(func() {
+		for job := range work {
+			ki := FromFeatures(trainingSet, features[job.termsPerClass], &PreprocessOptions{
+				Metric:    job.metric,
+				Weighting: job.weighting,
+			})
+
+			evaluations := make([]*evaluation.Evaluation, len(grid.Ks))
+			for i := range evaluations {
+				evaluations[i] = evaluation.New(testSet.ClassNames)
+			}
+
+			for docID := range testSet.Documents {
+				neighbours := ki.ExactNeighbours(&DocumentIndex{
+					Postings:    testSet.Forward.Postings,
+					PostingList: &testSet.Forward.PostingLists[docID],
+					Length:      testSet.Documents[docID].Length,
+				}, maxK, 1)
+
+				for i, k := range grid.Ks {
+					if k > len(neighbours) {
+						k = len(neighbours)
+					}
+					evaluations[i].Add(
+						testSet.Documents[docID].Name,
+						testSet.Documents[docID].Classes,
+						ki.bestClasses(neighbours[:k]),
+					)
+				}
+			}
+
+			for i, k := range grid.Ks {
+				results[job.index*len(grid.Ks)+i] = &TuneResult{
+					K:             k,
+					TermsPerClass: job.termsPerClass,
+					Metric:        job.metric,
+					Weighting:     job.weighting,
+					Summary:       evaluations[i].Summary(),
+				}
+			}
+
+			log.Printf(
+				"evaluated features per class: %d, metric: %s, weighting: %s",
+				job.termsPerClass, job.metric, job.weighting,
+			)
+		}
+	}, parallelWorkers)
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Summary.MicroF1 != results[j].Summary.MicroF1 {
+			return results[i].Summary.MicroF1 > results[j].Summary.MicroF1
+		}
+		return results[i].Summary.MacroF1 > results[j].Summary.MacroF1
+	})
+
+	return results
+}
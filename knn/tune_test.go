@@ -0,0 +1,43 @@
+package knn
+
+import (
+	"testing"
+
+	"github.com/DexterLB/search/sampling"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTune(t *testing.T) {
+	assert := assert.New(t)
+
+	ti := randomIndex(300, 1)
+	assignment := sampling.Random(len(ti.Documents), []float64{0.8, 0.2}, 1)
+	training := ti.Subset(sampling.Select(assignment, 0))
+	test := ti.Subset(sampling.Select(assignment, 1))
+
+	grid := &TuneGrid{
+		Ks:            []int{1, 5},
+		TermsPerClass: []int32{2, 10},
+		Metrics:       []Metric{Cosine, Jaccard},
+		Weightings:    []Weighting{TFIDF, LogTFIDF},
+	}
+	results := Tune(training, test, nil, grid, 2)
+
+	// jaccard ignores the weighting, so it's only tried once
+	assert.Equal(2*2*(2+1), len(results))
+	for i := 1; i < len(results); i++ {
+		assert.True(results[i-1].Summary.MicroF1 >= results[i].Summary.MicroF1)
+	}
+
+	// the results must match classifying with the same configuration
+	best := results[0]
+	ki := Preprocess(training, &PreprocessOptions{
+		TermsPerClass: best.TermsPerClass,
+		Metric:        best.Metric,
+		Weighting:     best.Weighting,
+	}, 2)
+	evaluation := InteractiveTest(func(document *DocumentIndex) []int32 {
+		return ki.ClassifyForward(document, best.K, 2)
+	}, test, false)
+	assert.Equal(best.Summary.MicroF1, evaluation.Summary().MicroF1)
+}
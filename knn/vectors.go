@@ -0,0 +1,110 @@
+package knn
+
+import (
+	"math"
+
+	"github.com/DexterLB/search/lsh"
+)
+
+// SparseVector is a document represented over the selected features.
+// Indices are positions in KNNInfo.Features (not term IDs) in ascending
+// order, and Weights holds the precomputed weight for each of them.
+type SparseVector struct {
+	Indices []int32
+	Weights []float64
+
+	SquaredNorm float64 // sum of squared weights
+	Sum         float64 // sum of weights
+}
+
+func (v *SparseVector) add(index int32, weight float64) {
+	v.Indices = append(v.Indices, index)
+	v.Weights = append(v.Weights, weight)
+	v.SquaredNorm += square(weight)
+	v.Sum += weight
+}
+
+// shared computes the dot product and the sum of the element-wise minimum
+// of two vectors over the features they have in common
+func shared(a *SparseVector, b *SparseVector) (dot float64, minSum float64) {
+	i, j := 0, 0
+	for i < len(a.Indices) && j < len(b.Indices) {
+		switch {
+		case a.Indices[i] < b.Indices[j]:
+			i++
+		case a.Indices[i] > b.Indices[j]:
+			j++
+		default:
+			dot += a.Weights[i] * b.Weights[j]
+			minSum += math.Min(a.Weights[i], b.Weights[j])
+			i++
+			j++
+		}
+	}
+	return
+}
+
+// ComputeVectors materialises the weighted feature vector of every training
+// document. It is called by Preprocess, and only needs to be called manually
+// for KNNInfo files created before vectors were stored.
+func (k *KNNInfo) ComputeVectors() {
+	k.Vectors = make([]SparseVector, len(k.Index.Forward.PostingLists))
+	for docID := range k.Vectors {
+		document := k.documentIndex(int32(docID))
+		k.forEachFeature(document, func(featureIndex int, count int32) {
+			k.Vectors[docID].add(int32(featureIndex), k.trainingValue(featureIndex, count, document))
+		})
+	}
+}
+
+// BuildHashes puts the training vectors into an LSH index and stores it in
+// the KNNInfo, so that ClassifyApproximate can be used
+func (k *KNNInfo) BuildHashes(params lsh.Params) {
+	k.Hashes = lsh.New(params)
+	for docID := range k.Vectors {
+		k.Hashes.Add(int32(docID), k.Vectors[docID].Indices, k.Vectors[docID].Weights)
+	}
+}
+
+// queryVector builds the vector of a document which is being classified
+func (k *KNNInfo) queryVector(document *DocumentIndex) *SparseVector {
+	vec := &SparseVector{}
+	k.forEachFeature(document, func(featureIndex int, count int32) {
+		vec.add(int32(featureIndex), k.value(featureIndex, count, document))
+	})
+	return vec
+}
+
+// forEachFeature calls operation for every selected feature which occurs
+// in the document, in order of feature index
+func (k *KNNInfo) forEachFeature(document *DocumentIndex, operation func(featureIndex int, count int32)) {
+	postingIndex := document.PostingList.FirstIndex
+	for i, featureID := range k.Features {
+		for postingIndex >= 0 && document.Postings[postingIndex].Index < featureID {
+			postingIndex = document.Postings[postingIndex].NextPostingIndex
+		}
+
+		if postingIndex == -1 {
+			return
+		}
+
+		if document.Postings[postingIndex].Index == featureID {
+			operation(i, document.Postings[postingIndex].Count)
+		}
+	}
+}
+
+// featureColumns transposes the training vectors, so that for every feature
+// the documents containing it can be looked up directly
+func (k *KNNInfo) featureColumns() []SparseVector {
+	k.columnsOnce.Do(func() {
+		k.columns = make([]SparseVector, len(k.Features))
+		for docID := range k.Vectors {
+			vec := &k.Vectors[docID]
+			for i, featureIndex := range vec.Indices {
+				k.columns[featureIndex].add(int32(docID), vec.Weights[i])
+			}
+		}
+	})
+	return k.columns
+}
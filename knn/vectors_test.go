@@ -0,0 +1,94 @@
+package knn
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/DexterLB/search/indices"
+	"github.com/stretchr/testify/assert"
+)
+
+func testIndex() *indices.TotalIndex {
+	ti := indices.NewTotalIndex()
+
+	add := func(name string, classes []string, terms map[string]int32) {
+		doc := indices.NewInfoAndTerms()
+		doc.Name = name
+		doc.Classes = classes
+		for term, count := range terms {
+			doc.TermsAndCounts.Put([]byte(term), count)
+			doc.Length += count
+		}
+		ti.Add(doc)
+	}
+
+	add("doc0", []string{"sports"}, map[string]int32{"ball": 3, "goal": 2, "team": 1})
+	add("doc1", []string{"sports"}, map[string]int32{"ball": 1, "team": 2, "coach": 1})
+	add("doc2", []string{"politics"}, map[string]int32{"vote": 2, "party": 2, "team": 1})
+	add("doc3", []string{"politics"}, map[string]int32{"vote": 1, "election": 3})
+
+	return ti
+}
+
+func sortedDistances(distances <-chan *DocumentDistance) []DocumentDistance {
+	var all []DocumentDistance
+	for dist := range distances {
+		all = append(all, *dist)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].DocumentID < all[j].DocumentID })
+	return all
+}
+
+func TestForwardAndInverseAgree(t *testing.T) {
+	assert := assert.New(t)
+
+	ti := testIndex()
+
+	for _, name := range MetricNames() {
+		metric, err := ParseMetric(name)
+		assert.Nil(err)
+
+		ki := Preprocess(ti, &PreprocessOptions{TermsPerClass: 3, Metric: metric}, 2)
+		assert.Equal(len(ti.Documents), len(ki.Vectors))
+
+		for docID := range ti.Documents {
+			query := ki.documentIndex(int32(docID))
+
+			forward := make(chan *DocumentDistance, 200)
+			go func() {
+				ki.forwardDistances(query, 2, forward)
+				close(forward)
+			}()
+
+			inverse := make(chan *DocumentDistance, 200)
+			go func() {
+				ki.distanceToAll(query, inverse)
+				close(inverse)
+			}()
+
+			forwardDistances := sortedDistances(forward)
+			inverseDistances := sortedDistances(inverse)
+
+			assert.Equal(len(forwardDistances), len(inverseDistances))
+			for i := range forwardDistances {
+				assert.Equal(forwardDistances[i].DocumentID, inverseDistances[i].DocumentID)
+				assert.InDelta(forwardDistances[i].Distance, inverseDistances[i].Distance, 1e-9, "metric %s", name)
+			}
+		}
+	}
+}
+
+func TestDocumentIsClosestToItself(t *testing.T) {
+	assert := assert.New(t)
+
+	ti := testIndex()
+
+	for _, metric := range []Metric{Euclidean, Cosine, Manhattan} {
+		ki := Preprocess(ti, &PreprocessOptions{TermsPerClass: 3, Metric: metric}, 2)
+		for docID := range ti.Documents {
+			query := ki.queryVector(ki.documentIndex(int32(docID)))
+			dot, minSum := shared(query, &ki.Vectors[docID])
+			assert.InDelta(0, metric.distance(query, &ki.Vectors[docID], dot, minSum), 1e-9, "metric %s", metric)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package knn
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Weighting selects how term counts are turned into vector weights for the
+// Euclidean, cosine and Manhattan metrics. The Jaccard and BM25 metrics
+// define their own weights and ignore it.
+type Weighting int32
+
+const (
+	// TFIDF is the term frequency (count / document length) times the
+	// inverse document frequency
+	TFIDF Weighting = iota
+	// LogTFIDF is 1 + log(count) times the inverse document frequency
+	LogTFIDF
+	// TF is the term frequency, without inverse document frequency
+	TF
+	// Binary is 1 for every present feature
+	Binary
+)
+
+var weightingNames = map[string]Weighting{
+	"tfidf":     TFIDF,
+	"log-tfidf": LogTFIDF,
+	"tf":        TF,
+	"binary":    Binary,
+}
+
+// ParseWeighting returns the weighting with the given name
+func ParseWeighting(name string) (Weighting, error) {
+	weighting, ok := weightingNames[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown weighting %s (available: %s)", name, strings.Join(WeightingNames(), ", "))
+	}
+	return weighting, nil
+}
+
+// WeightingNames lists the names accepted by ParseWeighting
+func WeightingNames() []string {
+	var names []string
+	for name := range weightingNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (w Weighting) String() string {
+	for name, weighting := range weightingNames {
+		if weighting == w {
+			return name
+		}
+	}
+	return fmt.Sprintf("weighting(%d)", int32(w))
+}
+
+// weight computes the weight of a term which occurs count times in a
+// document of the given length
+func (w Weighting) weight(count int32, length int32, idf float64) float64 {
+	switch w {
+	case LogTFIDF:
+		return (1 + math.Log(float64(count))) * idf
+	case TF:
+		return float64(count) / float64(length)
+	case Binary:
+		return 1
+	default:
+		return float64(count) / float64(length) * idf
+	}
+}
@@ -0,0 +1,206 @@
+package lsh
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/DexterLB/search/serialisation"
+)
+
+// Kind selects the hash family
+type Kind int32
+
+const (
+	// SimHash uses random hyperplanes and approximates cosine similarity
+	SimHash Kind = iota
+	// MinHash uses min-wise permutations of the present features and
+	// approximates Jaccard similarity
+	MinHash
+)
+
+var kindNames = map[string]Kind{
+	"simhash": SimHash,
+	"minhash": MinHash,
+}
+
+// ParseKind returns the hash family with the given name
+func ParseKind(name string) (Kind, error) {
+	kind, ok := kindNames[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown LSH kind %s (available: simhash, minhash)", name)
+	}
+	return kind, nil
+}
+
+func (k Kind) String() string {
+	for name, kind := range kindNames {
+		if kind == k {
+			return name
+		}
+	}
+	return fmt.Sprintf("kind(%d)", int32(k))
+}
+
+// Params configure the banding of the signatures: two vectors become
+// candidates if all Rows hashes of at least one of the Bands are equal
+type Params struct {
+	Kind  Kind
+	Bands int
+	Rows  int // at most 64 for SimHash
+	Seed  uint64
+}
+
+func DefaultParams() Params {
+	return Params{
+		Kind:  SimHash,
+		Bands: 20,
+		Rows:  8,
+		Seed:  1,
+	}
+}
+
+// Validate checks that an index can be built with the params
+func (p Params) Validate() error {
+	if p.Bands < 1 || p.Rows < 1 {
+		return fmt.Errorf("LSH needs a positive number of bands and rows")
+	}
+	if p.Kind == SimHash && p.Rows > 64 {
+		return fmt.Errorf("simhash supports at most 64 rows per band")
+	}
+	return nil
+}
+
+// Index is a banded LSH index over sparse vectors. Vectors are given as
+// ascending feature indices with a weight for each.
+type Index struct {
+	Params Params
+
+	// Buckets[band] maps a band hash to the IDs of the vectors in that bucket
+	Buckets []map[uint64][]int32
+	Size    int32
+}
+
+func New(params Params) *Index {
+	buckets := make([]map[uint64][]int32, params.Bands)
+	for i := range buckets {
+		buckets[i] = make(map[uint64][]int32)
+	}
+
+	return &Index{
+		Params:  params,
+		Buckets: buckets,
+	}
+}
+
+// Add puts a vector with the given ID into the index
+func (x *Index) Add(id int32, indices []int32, weights []float64) {
+	for band, key := range x.bandKeys(indices, weights) {
+		x.Buckets[band][key] = append(x.Buckets[band][key], id)
+	}
+	x.Size += 1
+}
+
+// Candidates returns the sorted IDs of all vectors which share a bucket
+// with the given one in at least one band
+func (x *Index) Candidates(indices []int32, weights []float64) []int32 {
+	seen := make(map[int32]struct{})
+	for band, key := range x.bandKeys(indices, weights) {
+		for _, id := range x.Buckets[band][key] {
+			seen[id] = struct{}{}
+		}
+	}
+
+	candidates := make([]int32, 0, len(seen))
+	for id := range seen {
+		candidates = append(candidates, id)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+
+	return candidates
+}
+
+func (x *Index) bandKeys(indices []int32, weights []float64) []uint64 {
+	if x.Params.Kind == MinHash {
+		return x.minHashKeys(indices)
+	}
+	return x.simHashKeys(indices, weights)
+}
+
+// simHashKeys computes one bit per hyperplane, packed into a key per band.
+// Hyperplane components are ±1, derived by hashing instead of being stored.
+func (x *Index) simHashKeys(indices []int32, weights []float64) []uint64 {
+	keys := make([]uint64, x.Params.Bands)
+	for band := range keys {
+		for row := 0; row < x.Params.Rows; row++ {
+			plane := uint64(band*x.Params.Rows + row)
+
+			projection := float64(0)
+			for i, index := range indices {
+				if x.hash(plane, index)&1 == 1 {
+					projection += weights[i]
+				} else {
+					projection -= weights[i]
+				}
+			}
+
+			if projection >= 0 {
+				keys[band] |= 1 << uint(row)
+			}
+		}
+	}
+	return keys
+}
+
+// minHashKeys computes the minimum hash of the present features for each
+// row, and combines the rows of a band into a key
+func (x *Index) minHashKeys(indices []int32) []uint64 {
+	keys := make([]uint64, x.Params.Bands)
+	for band := range keys {
+		key := uint64(band)
+		for row := 0; row < x.Params.Rows; row++ {
+			function := uint64(band*x.Params.Rows + row)
+
+			min := uint64(math.MaxUint64)
+			for _, index := range indices {
+				if h := x.hash(function, index); h < min {
+					min = h
+				}
+			}
+
+			key = mix(key ^ min)
+		}
+		keys[band] = key
+	}
+	return keys
+}
+
+func (x *Index) hash(function uint64, index int32) uint64 {
+	return mix(x.Params.Seed ^ mix(function<<32|uint64(uint32(index))))
+}
+
+// mix is the splitmix64 finaliser
+func mix(z uint64) uint64 {
+	z += 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (x *Index) SerialiseTo(w io.Writer) error {
+	return serialisation.SerialiseTo(x, w)
+}
+
+func (x *Index) SerialiseToFile(filename string) error {
+	return serialisation.SerialiseToFile(x, filename)
+}
+
+func (x *Index) DeserialiseFrom(r io.Reader) error {
+	return serialisation.DeserialiseFrom(x, r)
+}
+
+func (x *Index) DeserialiseFromFile(filename string) error {
+	return serialisation.DeserialiseFromFile(x, filename)
+}
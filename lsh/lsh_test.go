@@ -0,0 +1,69 @@
+package lsh
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCandidates(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, kind := range []Kind{SimHash, MinHash} {
+		params := DefaultParams()
+		params.Kind = kind
+		x := New(params)
+
+		x.Add(0, []int32{1, 2, 3, 4, 5, 6, 7, 8}, []float64{1, 1, 1, 1, 1, 1, 1, 1})
+		x.Add(1, []int32{1, 2, 3, 4, 5, 6, 7, 9}, []float64{1, 1, 1, 1, 1, 1, 1, 1})
+		x.Add(2, []int32{20, 21, 22, 23, 24, 25}, []float64{3, 1, 2, 1, 5, 1})
+
+		// an identical vector always ends up in the same buckets
+		candidates := x.Candidates([]int32{20, 21, 22, 23, 24, 25}, []float64{3, 1, 2, 1, 5, 1})
+		assert.Contains(candidates, int32(2), "kind %s", kind)
+		assert.NotContains(candidates, int32(0), "kind %s", kind)
+
+		// a nearly identical vector is very likely a candidate
+		candidates = x.Candidates([]int32{1, 2, 3, 4, 5, 6, 7, 8}, []float64{1, 1, 1, 1, 1, 1, 1, 1})
+		assert.Contains(candidates, int32(0), "kind %s", kind)
+		assert.Contains(candidates, int32(1), "kind %s", kind)
+		assert.NotContains(candidates, int32(2), "kind %s", kind)
+	}
+}
+
+func TestSerialisation(t *testing.T) {
+	assert := assert.New(t)
+
+	x := New(DefaultParams())
+	x.Add(0, []int32{1, 2, 3}, []float64{0.5, 0.25, 1})
+	x.Add(1, []int32{2, 5}, []float64{2, 1})
+
+	buf := &bytes.Buffer{}
+	assert.Nil(x.SerialiseTo(buf))
+
+	loaded := &Index{}
+	assert.Nil(loaded.DeserialiseFrom(buf))
+
+	assert.Equal(x.Params, loaded.Params)
+	assert.Equal(x.Size, loaded.Size)
+	assert.Equal(
+		x.Candidates([]int32{1, 2, 3}, []float64{0.5, 0.25, 1}),
+		loaded.Candidates([]int32{1, 2, 3}, []float64{0.5, 0.25, 1}),
+	)
+}
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(DefaultParams().Validate())
+
+	for _, params := range []Params{
+		{Kind: SimHash, Bands: 0, Rows: 8},
+		{Kind: MinHash, Bands: 20, Rows: -1},
+		{Kind: SimHash, Bands: 20, Rows: 65},
+	} {
+		assert.NotNil(params.Validate(), "%+v", params)
+	}
+	assert.Nil(Params{Kind: MinHash, Bands: 2, Rows: 100}.Validate())
+}
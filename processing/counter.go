@@ -1,8 +1,12 @@
 package processing
 
 import (
+	"strings"
+
+	"github.com/DexterLB/search/analysis"
 	"github.com/DexterLB/search/documents"
 	"github.com/DexterLB/search/indices"
+	"github.com/DexterLB/search/utils"
 )
 
 func CountInDocuments(
@@ -22,10 +26,60 @@ func CountInDocuments(
 	}
 }
 
+// CountInDocumentsInOrder is like CountInDocuments with parallel workers,
+// but sends the results in the order of the documents, so that the index
+// assigns the same IDs on every run
+func CountInDocumentsInOrder(
+	docs <-chan *documents.Document,
+	tokeniser Tokeniser,
+	idocs chan<- *indices.InfoAndTerms,
+	includeClassless bool,
+	includeClassy bool,
+	workers int,
+) {
+	jobs := make(chan func() func())
+	go func() {
+		for doc := range docs {
+			if len(doc.Classes) >= 1 && !includeClassy {
+				continue
+			}
+			if len(doc.Classes) == 0 && !includeClassless {
+				continue
+			}
+
+			doc := doc
+			jobs <- func() func() {
+				idoc := Count(doc, tokeniser)
+				return func() {
+					idocs <- idoc
+				}
+			}
+		}
+		close(jobs)
+	}()
+
+	utils.ParallelOrdered(jobs, workers)
+}
+
+// Count counts the terms of the document. If the tokeniser is (or wraps)
+// a multilingual analyser, the analyser for the language of the document
+// is used, and the language is detected unless it is known.
 func Count(doc *documents.Document, tokeniser Tokeniser) *indices.InfoAndTerms {
+	if multilingual(tokeniser) {
+		if doc.Language == "" {
+			doc.Language = analysis.DetectLanguage(doc.Title + "\n" + doc.Body)
+		}
+		tokeniser = forLanguage(tokeniser, doc.Language)
+	}
+
 	idoc := indices.NewInfoAndTerms()
-	idoc.Name = doc.Title
+	idoc.Name = doc.Name()
 	idoc.Classes = doc.Classes
+	idoc.Labels = doc.Labels
+	idoc.Language = doc.Language
+
+	// shingles don't count towards the length, which is the number of words
+	_, shingled := tokeniser.(*ShingleTokeniser)
 
 	tokeniser.GetTerms(doc.Title+" "+doc.Body, func(term string) {
 		idoc.TermsAndCounts.PutLambda(
@@ -33,7 +87,9 @@ func Count(doc *documents.Document, tokeniser Tokeniser) *indices.InfoAndTerms {
 			func(x int32) int32 { return x + 1 },
 			1,
 		)
-		idoc.Length += 1
+		if !shingled || !strings.Contains(term, ShingleSeparator) {
+			idoc.Length += 1
+		}
 	})
 
 	return idoc
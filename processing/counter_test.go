@@ -0,0 +1,45 @@
+package processing
+
+import (
+	"testing"
+
+	"github.com/DexterLB/search/analysis"
+	"github.com/DexterLB/search/documents"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountDetectsLanguageOnlyWhenNeeded(t *testing.T) {
+	assert := assert.New(t)
+
+	english, err := analysis.Preset("english")
+	assert.Nil(err)
+	plain, err := analysis.New(english)
+	assert.Nil(err)
+
+	doc := &documents.Document{Body: "Цените на петрола се повишиха рязко през последната седмица."}
+	Count(doc, plain)
+	assert.Equal("", doc.Language)
+
+	multilingual, err := analysis.New(analysis.MultilingualPreset())
+	assert.Nil(err)
+
+	idoc := Count(doc, multilingual)
+	assert.Equal("bulgarian", doc.Language)
+	assert.Equal("bulgarian", idoc.Language)
+}
+
+func TestCountKeepsKnownLanguage(t *testing.T) {
+	assert := assert.New(t)
+
+	multilingual, err := analysis.New(analysis.MultilingualPreset())
+	assert.Nil(err)
+
+	// the text reads as Bulgarian, but the source says it's Italian, which
+	// has no pipeline, so it's only split into lowercase words
+	doc := &documents.Document{Body: "Книгата на българските автори", Language: "italian"}
+	idoc := Count(doc, multilingual)
+
+	assert.Equal("italian", idoc.Language)
+	assert.NotNil(idoc.TermsAndCounts.Get([]byte("на")))
+	assert.NotNil(idoc.TermsAndCounts.Get([]byte("книгата")))
+}
@@ -0,0 +1,71 @@
+package processing
+
+import (
+	"strings"
+
+	"github.com/DexterLB/search/analysis"
+)
+
+// ShingleSeparator joins the words of a shingle into a single term, e.g.
+// "crude oil". Tokens never contain it, so shingles can't be confused with
+// single words.
+const ShingleSeparator = " "
+
+// ShingleTokeniser adds the word n-grams (shingles) of 2 to MaxSize
+// consecutive terms to the terms of another tokeniser. The shingles are
+// made after stop words are removed, so "price of oil" gives "price oil".
+type ShingleTokeniser struct {
+	Tokeniser
+	MaxSize int
+}
+
+func (s *ShingleTokeniser) GetTerms(text string, operation func(string)) {
+	var previous []string // the last MaxSize terms, ending with the current one
+
+	s.Tokeniser.GetTerms(text, func(term string) {
+		operation(term)
+
+		previous = append(previous, term)
+		if len(previous) > s.MaxSize {
+			previous = previous[1:]
+		}
+
+		for size := 2; size <= len(previous); size++ {
+			operation(strings.Join(previous[len(previous)-size:], ShingleSeparator))
+		}
+	})
+}
+
+// IsShingle tells if a term is made of several words
+func IsShingle(term []byte) bool {
+	return strings.Contains(string(term), ShingleSeparator)
+}
+
+// forLanguage returns the tokeniser for documents in the language, which
+// differs from the given one only for multilingual analysers
+func forLanguage(tokeniser Tokeniser, language string) Tokeniser {
+	switch t := tokeniser.(type) {
+	case *analysis.Analyser:
+		return t.ForLanguage(language)
+	case *ShingleTokeniser:
+		return &ShingleTokeniser{
+			Tokeniser: forLanguage(t.Tokeniser, language),
+			MaxSize:   t.MaxSize,
+		}
+	default:
+		return tokeniser
+	}
+}
+
+// multilingual tells if the tokeniser is (or wraps) a multilingual analyser,
+// which needs the language of the documents
+func multilingual(tokeniser Tokeniser) bool {
+	switch t := tokeniser.(type) {
+	case *analysis.Analyser:
+		return t.Multilingual()
+	case *ShingleTokeniser:
+		return multilingual(t.Tokeniser)
+	default:
+		return false
+	}
+}
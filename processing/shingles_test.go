@@ -0,0 +1,72 @@
+package processing
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DexterLB/search/analysis"
+	"github.com/DexterLB/search/documents"
+	"github.com/DexterLB/search/indices"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShingles(t *testing.T) {
+	assert := assert.New(t)
+
+	analyser, err := analysis.New(&analysis.Config{
+		Tokeniser: "unicode",
+		TokenFilters: []analysis.FilterConfig{
+			{Type: "lowercase"},
+			{Type: "stopwords", Words: []string{"of"}},
+		},
+	})
+	assert.Nil(err)
+
+	shingles := &ShingleTokeniser{Tokeniser: analyser, MaxSize: 3}
+
+	var terms []string
+	shingles.GetTerms("Price of crude oil", func(term string) {
+		terms = append(terms, term)
+	})
+	assert.Equal([]string{
+		"price",
+		"crude", "price crude",
+		"oil", "crude oil", "price crude oil",
+	}, terms)
+
+	idoc := Count(&documents.Document{Body: "Price of crude oil"}, shingles)
+	assert.Equal(int32(3), idoc.Length)
+	assert.Equal(int32(1), *idoc.TermsAndCounts.Get([]byte("price crude oil")))
+
+	assert.True(IsShingle([]byte("crude oil")))
+	assert.False(IsShingle([]byte("oil")))
+	assert.Equal(analyser, forLanguage(analyser, "english"))
+}
+
+func TestTokeniserForIndex(t *testing.T) {
+	assert := assert.New(t)
+
+	config, err := analysis.Preset("german")
+	assert.Nil(err)
+
+	index := indices.NewTotalIndex()
+	assert.Nil(RecordAnalyser(index, config))
+	index.ShingleSize = 2
+
+	// the config survives serialisation of the index
+	buf := &bytes.Buffer{}
+	assert.Nil(index.SerialiseTo(buf))
+	loaded := indices.NewTotalIndex()
+	assert.Nil(loaded.DeserialiseFrom(buf))
+
+	tokeniser, err := TokeniserForIndex(loaded, "")
+	assert.Nil(err)
+
+	shingles, ok := tokeniser.(*ShingleTokeniser)
+	assert.True(ok)
+	assert.Equal(2, shingles.MaxSize)
+
+	analyser, ok := shingles.Tokeniser.(*analysis.Analyser)
+	assert.True(ok)
+	assert.Equal(config, analyser.Config)
+}
@@ -1,8 +1,55 @@
 package processing
 
+import (
+	"github.com/DexterLB/search/analysis"
+	"github.com/DexterLB/search/indices"
+)
+
 type Tokeniser interface {
 	Tokenise(text string) []string
 	Normalise(token string) string
 	IsStopWord(word string) bool
 	GetTerms(text string, operation func(string))
 }
+
+// TokeniserForIndex returns the analyser recorded in the index, so that
+// new text is analysed like its documents were, or an EnglishTokeniser
+// with the stop words from the file if the index has none. Shingles are
+// added if the index has them.
+func TokeniserForIndex(index *indices.TotalIndex, stopWordFile string) (Tokeniser, error) {
+	var tokeniser Tokeniser
+	var err error
+	if index.Analyser != nil {
+		var config *analysis.Config
+		config, err = analysis.ParseConfig(index.Analyser)
+		if err == nil {
+			tokeniser, err = analysis.New(config)
+		}
+	} else {
+		tokeniser, err = NewEnglishTokeniserFromFile(stopWordFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if index.ShingleSize > 1 {
+		tokeniser = &ShingleTokeniser{Tokeniser: tokeniser, MaxSize: int(index.ShingleSize)}
+	}
+	return tokeniser, nil
+}
+
+// RecordAnalyser stores the analyser config in the index, so that
+// TokeniserForIndex can recreate the analyser
+func RecordAnalyser(index *indices.TotalIndex, config *analysis.Config) error {
+	if config == nil {
+		index.Analyser = nil
+		return nil
+	}
+
+	data, err := config.Encode()
+	if err != nil {
+		return err
+	}
+	index.Analyser = data
+	return nil
+}
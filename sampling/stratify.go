@@ -0,0 +1,180 @@
+package sampling
+
+import (
+	"math/rand"
+)
+
+// Stratify assigns every document to one of len(proportions) subsets, so
+// that each subset gets approximately its proportion of the documents, and
+// also of the documents of each class. classes[i] are the classes of
+// document i. It uses the iterative stratification algorithm of Sechidis,
+// Tsoumakas and Vlahavas, which handles documents with multiple classes by
+// always placing the documents of the rarest remaining class first.
+func Stratify(classes [][]int32, proportions []float64, seed int64) []int {
+	random := rand.New(rand.NewSource(seed))
+	numSubsets := len(proportions)
+
+	// documents of each class, in random order
+	order := random.Perm(len(classes))
+	classDocuments := make(map[int32][]int)
+	for _, doc := range order {
+		for _, class := range classes[doc] {
+			classDocuments[class] = append(classDocuments[class], doc)
+		}
+	}
+
+	desired := make([]float64, numSubsets)
+	desiredForClass := make(map[int32][]float64)
+	for subset, proportion := range proportions {
+		desired[subset] = proportion * float64(len(classes))
+	}
+	for class, docs := range classDocuments {
+		desiredForClass[class] = make([]float64, numSubsets)
+		for subset, proportion := range proportions {
+			desiredForClass[class][subset] = proportion * float64(len(docs))
+		}
+	}
+
+	assignment := make([]int, len(classes))
+	for i := range assignment {
+		assignment[i] = -1
+	}
+
+	assign := func(doc int, subset int) {
+		assignment[doc] = subset
+		desired[subset] -= 1
+		for _, class := range classes[doc] {
+			desiredForClass[class][subset] -= 1
+		}
+	}
+
+	remaining := make(map[int32]int)
+	for class, docs := range classDocuments {
+		remaining[class] = len(docs)
+	}
+
+	for len(remaining) > 0 {
+		// the class with the fewest unassigned documents
+		rarest := int32(-1)
+		for class, count := range remaining {
+			if rarest == -1 || count < remaining[rarest] || (count == remaining[rarest] && class < rarest) {
+				rarest = class
+			}
+		}
+
+		for _, doc := range classDocuments[rarest] {
+			if assignment[doc] != -1 {
+				continue
+			}
+
+			subset := best(desiredForClass[rarest], desired, random)
+			assign(doc, subset)
+
+			for _, class := range classes[doc] {
+				remaining[class] -= 1
+				if remaining[class] <= 0 {
+					delete(remaining, class)
+				}
+			}
+		}
+		delete(remaining, rarest)
+	}
+
+	// documents without classes only need to fill the subsets up
+	for _, doc := range order {
+		if assignment[doc] == -1 {
+			assign(doc, best(desired, desired, random))
+		}
+	}
+
+	return assignment
+}
+
+// StratifiedFolds assigns every document to one of k folds of equal size
+// using Stratify
+func StratifiedFolds(classes [][]int32, k int, seed int64) []int {
+	proportions := make([]float64, k)
+	for i := range proportions {
+		proportions[i] = 1 / float64(k)
+	}
+	return Stratify(classes, proportions, seed)
+}
+
+// Random assigns every one of n documents to one of len(proportions)
+// subsets at random, so that each subset gets its proportion of documents
+func Random(n int, proportions []float64, seed int64) []int {
+	random := rand.New(rand.NewSource(seed))
+	assignment := make([]int, n)
+
+	total := float64(0)
+	for _, proportion := range proportions {
+		total += proportion
+	}
+
+	i := 0
+	cumulative := float64(0)
+	for subset, proportion := range proportions {
+		cumulative += proportion
+		end := int(cumulative/total*float64(n) + 0.5)
+		if subset == len(proportions)-1 {
+			end = n
+		}
+		for ; i < end; i++ {
+			assignment[i] = subset
+		}
+	}
+
+	random.Shuffle(n, func(a, b int) {
+		assignment[a], assignment[b] = assignment[b], assignment[a]
+	})
+
+	return assignment
+}
+
+// Select returns the indices of the documents assigned to the given subset
+func Select(assignment []int, subset int) []int32 {
+	var selected []int32
+	for doc, s := range assignment {
+		if s == subset {
+			selected = append(selected, int32(doc))
+		}
+	}
+	return selected
+}
+
+// Exclude returns the indices of the documents not assigned to the given
+// subset
+func Exclude(assignment []int, subset int) []int32 {
+	var selected []int32
+	for doc, s := range assignment {
+		if s != subset {
+			selected = append(selected, int32(doc))
+		}
+	}
+	return selected
+}
+
+// best picks the subset which most needs documents of a class, breaking
+// ties by which subset most needs documents in general, and then randomly
+func best(desiredForClass []float64, desired []float64, random *rand.Rand) int {
+	var candidates []int
+	for subset := range desiredForClass {
+		if len(candidates) == 0 {
+			candidates = []int{subset}
+			continue
+		}
+
+		first := candidates[0]
+		switch {
+		case desiredForClass[subset] > desiredForClass[first]:
+			candidates = []int{subset}
+		case desiredForClass[subset] < desiredForClass[first]:
+		case desired[subset] > desired[first]:
+			candidates = []int{subset}
+		case desired[subset] == desired[first]:
+			candidates = append(candidates, subset)
+		}
+	}
+
+	return candidates[random.Intn(len(candidates))]
+}
@@ -0,0 +1,56 @@
+package sampling
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStratifiedFolds(t *testing.T) {
+	assert := assert.New(t)
+
+	random := rand.New(rand.NewSource(42))
+	classes := make([][]int32, 1000)
+	for i := range classes {
+		// a frequent class, a rare class and some unlabelled documents
+		switch r := random.Intn(100); {
+		case r < 70:
+			classes[i] = []int32{0}
+		case r < 80:
+			classes[i] = []int32{0, 1}
+		case r < 90:
+			classes[i] = []int32{1}
+		}
+	}
+
+	folds := StratifiedFolds(classes, 5, 1)
+	assert.Equal(folds, StratifiedFolds(classes, 5, 1))
+
+	sizes := make([]int, 5)
+	classSizes := make([][2]int, 5)
+	total := [2]int{}
+	for doc, fold := range folds {
+		sizes[fold] += 1
+		for _, class := range classes[doc] {
+			classSizes[fold][class] += 1
+			total[class] += 1
+		}
+	}
+
+	for fold := range sizes {
+		assert.InDelta(200, sizes[fold], 2)
+		for class := range total {
+			assert.InDelta(float64(total[class])/5, classSizes[fold][class], 2)
+		}
+	}
+}
+
+func TestRandom(t *testing.T) {
+	assert := assert.New(t)
+
+	assignment := Random(10, []float64{0.7, 0.3}, 1)
+	assert.Equal(7, len(Select(assignment, 0)))
+	assert.Equal(3, len(Select(assignment, 1)))
+	assert.Equal(Select(assignment, 1), Exclude(assignment, 0))
+}
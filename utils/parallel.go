@@ -37,3 +37,40 @@ func ParallelLog(work func() error, workers int) {
 	ParallelCheck(work, workers, errors)
 	close(errors)
 }
+
+// ParallelOrdered runs the jobs from the channel with parallel workers.
+// Each job returns a function (or nil) to finish it, and those are called
+// one at a time, in the order the jobs came, so that the result doesn't
+// depend on which worker was faster. Workers run at most a few jobs ahead
+// of the oldest unfinished one.
+func ParallelOrdered(jobs <-chan func() func(), workers int) {
+	type task struct {
+		job    func() func()
+		finish chan func()
+	}
+
+	tasks := make(chan task, workers)
+	pending := make(chan chan func(), 2*workers)
+
+	go func() {
+		for job := range jobs {
+			finish := make(chan func(), 1)
+			tasks <- task{job: job, finish: finish}
+			pending <- finish
+		}
+		close(tasks)
+		close(pending)
+	}()
+
+	go Parallel(func() {
+		for t := range tasks {
+			t.finish <- t.job()
+		}
+	}, workers)
+
+	for finish := range pending {
+		if f := <-finish; f != nil {
+			f()
+		}
+	}
+}
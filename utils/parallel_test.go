@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallelOrdered(t *testing.T) {
+	var order []int
+
+	jobs := make(chan func() func())
+	go func() {
+		for i := 0; i < 100; i++ {
+			i := i
+			jobs <- func() func() {
+				// later jobs finish first
+				time.Sleep(time.Duration(100-i) * 10 * time.Microsecond)
+				if i%10 == 0 {
+					return nil
+				}
+				return func() {
+					order = append(order, i)
+				}
+			}
+		}
+		close(jobs)
+	}()
+
+	ParallelOrdered(jobs, 8)
+
+	var expected []int
+	for i := 0; i < 100; i++ {
+		if i%10 != 0 {
+			expected = append(expected, i)
+		}
+	}
+	assert.Equal(t, expected, order)
+}